@@ -0,0 +1,72 @@
+package safesvg
+
+import (
+	"testing"
+)
+
+var benchSVG = []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="24" height="24" viewBox="0 0 24 24">
+<defs><linearGradient id="g" x1="0" y1="0" x2="1" y2="1"><stop offset="0" stop-color="#fff"/><stop offset="1" stop-color="#000"/></linearGradient>
+<clipPath id="c"><rect width="24" height="24" rx="4"/></clipPath></defs>
+<g clip-path="url(#c)" fill="url(#g)" stroke="#333" stroke-width="1.5" stroke-linecap="round">
+<path d="M12 2L2 7l10 5 10-5-10-5z"/><path d="M2 17l10 5 10-5"/><path d="M2 12l10 5 10-5"/>
+<circle cx="12" cy="12" r="3" fill-opacity="0.5"/><use xlink:href="#c" x="1" y="1"/>
+</g>
+</svg>`)
+
+func benchmarkValidate(b *testing.B, parser Parser) {
+	v := NewValidator()
+	v.SetParser(parser)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchSVG)))
+	for i := 0; i < b.N; i++ {
+		if err := v.Validate(benchSVG); err != nil {
+			b.Fatalf("Unexptected error %v", err)
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	benchmarkValidate(b, ParserEncodingXML)
+}
+
+func BenchmarkValidateTdewolff(b *testing.B) {
+	benchmarkValidate(b, ParserTdewolff)
+}
+
+func BenchmarkSanitize(b *testing.B) {
+	v := NewValidator()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchSVG)))
+	for i := 0; i < b.N; i++ {
+		if _, err := v.Sanitize(benchSVG); err != nil {
+			b.Fatalf("Unexptected error %v", err)
+		}
+	}
+}
+
+func BenchmarkSanitizeTdewolff(b *testing.B) {
+	v := NewValidator()
+	v.SetParser(ParserTdewolff)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchSVG)))
+	for i := 0; i < b.N; i++ {
+		if _, err := v.Sanitize(benchSVG); err != nil {
+			b.Fatalf("Unexptected error %v", err)
+		}
+	}
+}
+
+func BenchmarkValidateParallel(b *testing.B) {
+	v := NewValidator()
+	v.SetParser(ParserTdewolff)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchSVG)))
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := v.Validate(benchSVG); err != nil {
+				b.Fatalf("Unexptected error %v", err)
+			}
+		}
+	})
+}
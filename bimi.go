@@ -0,0 +1,213 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// the documents referenced by the deviations of ValidateBIMI
+const (
+	specTinyPS    = `https://datatracker.ietf.org/doc/draft-svg-tiny-ps-abrotman/`
+	specStructure = `https://www.w3.org/TR/SVGTiny12/struct.html`
+	specScript    = `https://www.w3.org/TR/SVGTiny12/script.html`
+	specAnimation = `https://www.w3.org/TR/SVGTiny12/animate.html`
+	specLinking   = `https://www.w3.org/TR/SVGTiny12/linking.html`
+)
+
+// tinyPSElements are the elements of SVG Tiny Portable/Secure
+var tinyPSElements = map[string]struct{}{
+	`svg`: {}, `g`: {}, `defs`: {}, `title`: {}, `desc`: {}, `use`: {},
+	`path`: {}, `rect`: {}, `circle`: {}, `ellipse`: {}, `line`: {},
+	`polyline`: {}, `polygon`: {}, `solidColor`: {}, `text`: {}, `tspan`: {},
+	`textArea`: {}, `tbreak`: {}, `linearGradient`: {}, `radialGradient`: {},
+	`stop`: {},
+}
+
+// tinyPSAttributes are the SVG Tiny PS attributes missing from the default
+// whitelist
+var tinyPSAttributes = []string{
+	`version`, `baseProfile`, `solid-color`, `solid-opacity`, `line-increment`, `display-align`,
+}
+
+var tinyPSAnimationElements = map[string]struct{}{
+	`animate`: {}, `set`: {}, `animateColor`: {}, `animateMotion`: {},
+	`animateTransform`: {}, `mpath`: {}, `discard`: {},
+}
+
+// BIMIDeviation is a difference between a document and SVG Tiny PS
+type BIMIDeviation struct {
+	Path      string // of the element, e.g. /svg/g/image
+	Message   string
+	Reference string // url of the rule
+}
+
+func (d BIMIDeviation) String() string {
+	return d.Path + `: ` + d.Message + ` (` + d.Reference + `)`
+}
+
+// BIMIError lists every deviation found by ValidateBIMI
+type BIMIError struct {
+	Deviations []BIMIDeviation
+}
+
+func (e *BIMIError) Error() string {
+	list := make([]string, len(e.Deviations))
+	for i, d := range e.Deviations {
+		list[i] = d.String()
+	}
+	return ErrNotTinyPS.Error() + `: ` + strings.Join(list, `; `)
+}
+
+func (e *BIMIError) Unwrap() error {
+	return ErrNotTinyPS
+}
+
+// ValidateBIMI checks that b is a BIMI logo in SVG Tiny Portable/Secure:
+// a root svg with version="1.2", baseProfile="tiny-ps", a <title> and no x
+// or y, only the SVG Tiny PS elements, no scripts, event handlers or
+// animation, and no external references. Every deviation is reported in a
+// *BIMIError. A conforming document is then validated with the checks of the
+// validator, whose whitelists are extended with the SVG Tiny PS names.
+func (vld Validator) ValidateBIMI(b []byte) error {
+	if err := vld.checkTinyPS(bytes.NewReader(b)); err != nil {
+		return err
+	}
+	tinyPS := vld.Clone()
+	tinyPS.WhitelistElements(mapKeys(tinyPSElements)...)
+	tinyPS.WhitelistAttributes(tinyPSAttributes...)
+	return tinyPS.Validate(b)
+}
+
+// checkTinyPS collects the deviations from SVG Tiny PS
+func (vld *Validator) checkTinyPS(r io.Reader) error {
+	w := vld.newWalker(nil)
+	t, err := w.newTokenizer(r)
+	if err != nil {
+		return err
+	}
+	var (
+		deviations []BIMIDeviation
+		path       []string
+		rootSeen   bool
+		title      bool
+		inStyle    bool
+	)
+	deviate := func(message, reference string) {
+		deviations = append(deviations, BIMIDeviation{
+			Path:      `/` + strings.Join(path, `/`),
+			Message:   message,
+			Reference: reference,
+		})
+	}
+	for {
+		to, err := t.Token()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				break
+			}
+			return err
+		}
+		switch v := to.(type) {
+		case xml.StartElement:
+			path = append(path, v.Name.Local)
+			if len(path) == 1 {
+				if rootSeen {
+					deviate(`element after the root element`, specTinyPS)
+					continue
+				}
+				rootSeen = true
+				checkTinyPSRoot(v, deviate)
+			} else if len(path) == 2 && v.Name.Local == `title` && v.Name.Space == nsSVG {
+				title = true
+			}
+			inStyle = v.Name.Local == `style`
+			checkTinyPSElement(v, deviate)
+			for _, attr := range v.Attr {
+				key := strings.ToLower(attr.Name.Local)
+				switch attr.Name.Space {
+				case nsXLink:
+					key = `xlink:` + key
+				case nsXML:
+					key = `xml:` + key
+				}
+				if strings.HasPrefix(key, `on`) {
+					deviate(fmt.Sprintf(`event handler %s`, attr.Name.Local), specScript)
+				}
+				for _, ref := range attrNetworkReferences(key, attr.Value) {
+					deviate(`external reference `+ref, specLinking)
+				}
+			}
+		case xml.EndElement:
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			inStyle = false
+		case xml.CharData:
+			if inStyle {
+				for _, ref := range cssNetworkReferences(v) {
+					deviate(`external reference `+ref, specLinking)
+				}
+			}
+		case xml.ProcInst:
+			if v.Target == `xml-stylesheet` {
+				deviate(`external stylesheet`, specLinking)
+			}
+		}
+	}
+	if !rootSeen {
+		deviate(`missing root svg element`, specStructure)
+	} else if !title {
+		path = path[:0]
+		deviate(`missing <title> child of the root svg element`, specTinyPS)
+	}
+	if len(deviations) > 0 {
+		return &BIMIError{Deviations: deviations}
+	}
+	return nil
+}
+
+func checkTinyPSRoot(v xml.StartElement, deviate func(message, reference string)) {
+	if v.Name.Local != `svg` || v.Name.Space != nsSVG {
+		deviate(fmt.Sprintf(`root element <%s> is not svg in the %s namespace`, v.Name.Local, nsSVG), specStructure)
+		return
+	}
+	var version, baseProfile string
+	for _, attr := range v.Attr {
+		if len(attr.Name.Space) > 0 {
+			continue
+		}
+		switch attr.Name.Local {
+		case `version`:
+			version = attr.Value
+		case `baseProfile`:
+			baseProfile = attr.Value
+		case `x`, `y`:
+			deviate(fmt.Sprintf(`attribute %s on the root element`, attr.Name.Local), specTinyPS)
+		}
+	}
+	if version != `1.2` {
+		deviate(fmt.Sprintf(`version must be "1.2", got %q`, version), specTinyPS)
+	}
+	if baseProfile != `tiny-ps` {
+		deviate(fmt.Sprintf(`baseProfile must be "tiny-ps", got %q`, baseProfile), specTinyPS)
+	}
+}
+
+func checkTinyPSElement(v xml.StartElement, deviate func(message, reference string)) {
+	name := v.Name.Local
+	switch _, animation := tinyPSAnimationElements[name]; {
+	case v.Name.Space != nsSVG:
+		deviate(fmt.Sprintf(`element <%s> is not in the %s namespace`, name, nsSVG), specTinyPS)
+	case name == `script` || name == `handler` || name == `listener`:
+		deviate(fmt.Sprintf(`script element <%s>`, name), specScript)
+	case animation:
+		deviate(fmt.Sprintf(`animation element <%s>`, name), specAnimation)
+	default:
+		if _, ok := tinyPSElements[name]; !ok {
+			deviate(fmt.Sprintf(`element <%s> is not in SVG Tiny PS`, name), specTinyPS)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package safesvg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_ValidateBIMI(t *testing.T) {
+	v := NewValidator()
+	logo := `<svg xmlns="http://www.w3.org/2000/svg" version="1.2" baseProfile="tiny-ps" viewBox="0 0 100 100">` +
+		`<title>Example</title><circle cx="50" cy="50" r="40" fill="#f00" solid-opacity="1"/></svg>`
+	if err := v.ValidateBIMI([]byte(logo)); err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if err := v.Validate([]byte(logo)); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Expected the validator to be unchanged, got %v", err)
+	}
+
+	bad := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" version="1.1" x="0">` +
+		`<script>alert(1)</script><rect onclick="a()"/><animate/><image xlink:href="https://example.com/a.png"/>` +
+		`<g><use href="#a"/></g></svg>`
+	err := v.ValidateBIMI([]byte(bad))
+	if !errors.Is(err, ErrNotTinyPS) {
+		t.Fatalf("Expected %v, got %v", ErrNotTinyPS, err)
+	}
+	var bimi *BIMIError
+	if !errors.As(err, &bimi) {
+		t.Fatalf("Expected a *BIMIError, got %T", err)
+	}
+	for _, expected := range []string{
+		`/svg: attribute x on the root element`,
+		`/svg: version must be "1.2", got "1.1"`,
+		`/svg: baseProfile must be "tiny-ps", got ""`,
+		`/svg/script: script element <script> (` + specScript + `)`,
+		`/svg/rect: event handler onclick`,
+		`/svg/animate: animation element <animate>`,
+		`/svg/image: element <image> is not in SVG Tiny PS`,
+		`/svg/image: external reference xlink:href="https://example.com/a.png"`,
+		`/: missing <title> child of the root svg element`,
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in %v", expected, err)
+		}
+	}
+	if len(bimi.Deviations) != 9 {
+		t.Errorf("Expected 9 deviations, got %d: %v", len(bimi.Deviations), bimi.Deviations)
+	}
+
+	external := strings.Replace(logo, `<title>`, `<style>@import url(https://example.com/a.css);</style><title>`, 1)
+	if err := v.ValidateBIMI([]byte(`<?xml-stylesheet href="a.css"?>` + external)); !errors.Is(err, ErrNotTinyPS) ||
+		!strings.Contains(err.Error(), `external stylesheet`) || !strings.Contains(err.Error(), `external reference @import`) {
+		t.Errorf("Expected the external references, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package safesvg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// SetStrictCase requires element and attribute names to be spelled exactly
+// like in the whitelists (e.g. viewBox, clipPath, feGaussianBlur), as browsers
+// treat any other spelling as an unknown name. In sanitize mode the names are
+// corrected instead.
+func (vld *Validator) SetStrictCase(strict bool) *Validator {
+	vld.strictCase = strict
+	return vld
+}
+
+func mapKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// checkCase checks the spelling of a whitelisted element name, correcting it in
+// sanitize mode
+func (w *walker) checkCase(name *xml.Name, elem string) error {
+	canonical := w.vld.whiteListElements[elem]
+	if name.Local == canonical {
+		return nil
+	}
+	if w.sanitize() {
+		name.Local = canonical
+		return nil
+	}
+	return fmt.Errorf("%w: %s (expected %s)", ErrInvalidElement, name.Local, canonical)
+}
+
+// checkAttrCase checks the spelling of a whitelisted attribute name, correcting
+// it in sanitize mode
+func (w *walker) checkAttrCase(attr *xml.Attr, key string) error {
+	canonical, _ := w.vld.attributeName(w.elem, key)
+	if i := strings.LastIndex(canonical, `:`); i >= 0 {
+		canonical = canonical[i+1:]
+	}
+	if attr.Name.Local == canonical {
+		return nil
+	}
+	if w.sanitize() {
+		attr.Name.Local = canonical
+		return nil
+	}
+	return fmt.Errorf("%w: %s (expected %s)", ErrInvalidAttribute, attr.Name.Local, canonical)
+}
@@ -0,0 +1,179 @@
+package safesvg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// SetRequireUTF8 rejects documents encoded in anything other than UTF-8 (or
+// its US-ASCII subset) instead of converting them
+func (vld *Validator) SetRequireUTF8(require bool) *Validator {
+	vld.requireUTF8 = require
+	return vld
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	// "<?" without byte order mark
+	utf16LE = []byte{'<', 0, '?', 0}
+	utf16BE = []byte{0, '<', 0, '?'}
+)
+
+// newTokenizer returns a tokenizer reading UTF-8 from r, decompressing gzip
+// input and converting UTF-16 input detected by its byte order mark or
+// leading "<?" and the charsets declared by the xml declaration
+func (w *walker) newTokenizer(r io.Reader) (tokenizer, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(4)
+	if bytes.HasPrefix(head, gzipMagic) {
+		gz, err := w.vld.gunzip(br)
+		if err != nil {
+			return nil, err
+		}
+		br = bufio.NewReader(gz)
+		head, _ = br.Peek(4)
+	}
+	var (
+		input   io.Reader = br
+		charset string
+	)
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		br.Discard(len(bomUTF8))
+	case bytes.HasPrefix(head, bomUTF16LE):
+		br.Discard(len(bomUTF16LE))
+		charset = `utf-16le`
+	case bytes.HasPrefix(head, bomUTF16BE):
+		br.Discard(len(bomUTF16BE))
+		charset = `utf-16be`
+	case bytes.HasPrefix(head, utf16LE):
+		charset = `utf-16le`
+	case bytes.HasPrefix(head, utf16BE):
+		charset = `utf-16be`
+	}
+	if len(charset) > 0 {
+		if w.vld.requireUTF8 {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, charset)
+		}
+		input = &utf16Reader{r: br, bigEndian: charset == `utf-16be`}
+	}
+	charsetReader := func(label string, input io.Reader) (io.Reader, error) {
+		name := strings.ToLower(strings.TrimSpace(label))
+		switch {
+		case len(charset) > 0 && strings.HasPrefix(name, `utf-16`):
+			// already converted after sniffing the input
+			return input, nil
+		case name == `us-ascii` || name == `ascii`:
+			return input, nil
+		case w.vld.requireUTF8:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, label)
+		}
+		return newCharsetReader(name, input)
+	}
+	if w.vld.parser == ParserTdewolff {
+		return newLexTokenizer(input, charsetReader)
+	}
+	t := xml.NewDecoder(input)
+	t.CharsetReader = charsetReader
+	return t, nil
+}
+
+// newCharsetReader returns a reader converting the charset to UTF-8
+func newCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch charset {
+	case `iso-8859-1`, `iso8859-1`, `iso_8859-1`, `latin1`, `latin-1`, `l1`:
+		return &singleByteReader{r: bufio.NewReader(input)}, nil
+	case `windows-1252`, `cp1252`, `x-cp1252`:
+		return &singleByteReader{r: bufio.NewReader(input), high: &windows1252}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCharset, charset)
+}
+
+// windows1252 maps the bytes 0x80-0x9F, the rest is the same as ISO-8859-1
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// singleByteReader converts ISO-8859-1, or windows-1252 if high is set, to UTF-8
+type singleByteReader struct {
+	r    *bufio.Reader
+	high *[32]rune
+	buf  []byte
+}
+
+func (s *singleByteReader) Read(p []byte) (int, error) {
+	for len(s.buf) < len(p) {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			if len(s.buf) > 0 {
+				break
+			}
+			return 0, err
+		}
+		r := rune(c)
+		if s.high != nil && c >= 0x80 && c < 0xA0 {
+			r = s.high[c-0x80]
+		}
+		s.buf = utf8.AppendRune(s.buf, r)
+	}
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+	return n, nil
+}
+
+// utf16Reader converts UTF-16 to UTF-8
+type utf16Reader struct {
+	r         *bufio.Reader
+	bigEndian bool
+	buf       []byte
+}
+
+func (u *utf16Reader) readUnit() (uint16, error) {
+	var b [2]byte
+	if _, err := io.ReadFull(u.r, b[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return 0, fmt.Errorf("%w: truncated utf-16 input", ErrUnsupportedCharset)
+		}
+		return 0, err
+	}
+	if u.bigEndian {
+		return uint16(b[0])<<8 | uint16(b[1]), nil
+	}
+	return uint16(b[1])<<8 | uint16(b[0]), nil
+}
+
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	for len(u.buf) < len(p) {
+		c, err := u.readUnit()
+		if err != nil {
+			if len(u.buf) > 0 && err == io.EOF {
+				break
+			}
+			return 0, err
+		}
+		r := rune(c)
+		if utf16.IsSurrogate(r) {
+			c2, err := u.readUnit()
+			if err != nil {
+				if err == io.EOF {
+					err = fmt.Errorf("%w: truncated utf-16 input", ErrUnsupportedCharset)
+				}
+				return 0, err
+			}
+			r = utf16.DecodeRune(r, rune(c2))
+		}
+		u.buf = utf8.AppendRune(u.buf, r)
+	}
+	n := copy(p, u.buf)
+	u.buf = u.buf[n:]
+	return n, nil
+}
@@ -0,0 +1,67 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, bigEndian bool, bom bool) []byte {
+	var b []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	for _, u := range units {
+		if bigEndian {
+			b = append(b, byte(u>>8), byte(u))
+		} else {
+			b = append(b, byte(u), byte(u>>8))
+		}
+	}
+	return b
+}
+
+func Test_Charset(t *testing.T) {
+	svg := `<?xml version="1.0" encoding="UTF-16"?><svg xmlns="http://www.w3.org/2000/svg"><title>Grüße 😀</title></svg>`
+	v := NewValidator()
+	for _, b := range [][]byte{
+		encodeUTF16(svg, false, true),
+		encodeUTF16(svg, true, true),
+		encodeUTF16(svg, false, false),
+		encodeUTF16(svg, true, false),
+		append([]byte{0xEF, 0xBB, 0xBF}, `<svg xmlns="http://www.w3.org/2000/svg"/>`...),
+	} {
+		if err := v.Validate(b); err != nil {
+			t.Errorf("Unexptected error %v", err)
+		}
+	}
+	out, err := v.Sanitize(encodeUTF16(svg, true, true))
+	if err != nil || string(out) != `<?xml version="1.0" encoding="UTF-8"?><svg xmlns="http://www.w3.org/2000/svg"><title>Grüße 😀</title></svg>` {
+		t.Errorf("Unexpected output %s, error %v", out, err)
+	}
+
+	latin1 := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><svg xmlns=\"http://www.w3.org/2000/svg\"><title>Gr\xfc\xdfe \x80</title></svg>")
+	out, err = v.Sanitize(latin1)
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?><svg xmlns=\"http://www.w3.org/2000/svg\"><title>Grüße \u0080</title></svg>" {
+		t.Errorf("Unexpected output %s, error %v", out, err)
+	}
+	cp1252 := []byte("<?xml version=\"1.0\" encoding=\"windows-1252\"?><svg xmlns=\"http://www.w3.org/2000/svg\"><title>\x80 \x93a\x94</title></svg>")
+	out, err = v.Sanitize(cp1252)
+	if err != nil || string(out) != "<?xml version=\"1.0\" encoding=\"UTF-8\"?><svg xmlns=\"http://www.w3.org/2000/svg\"><title>€ “a”</title></svg>" {
+		t.Errorf("Unexpected output %s, error %v", out, err)
+	}
+	if err = v.Validate([]byte(`<?xml version="1.0" encoding="EBCDIC"?><svg xmlns="http://www.w3.org/2000/svg"/>`)); !errors.Is(err, ErrUnsupportedCharset) {
+		t.Errorf("Expected %v, got %v", ErrUnsupportedCharset, err)
+	}
+
+	v.SetRequireUTF8(true)
+	for _, b := range [][]byte{latin1, encodeUTF16(svg, false, true)} {
+		if err = v.Validate(b); !errors.Is(err, ErrUnsupportedCharset) {
+			t.Errorf("Expected %v, got %v", ErrUnsupportedCharset, err)
+		}
+	}
+	if err = v.Validate([]byte(`<?xml version="1.0" encoding="utf-8"?><svg xmlns="http://www.w3.org/2000/svg"/>`)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package safesvg
+
+import (
+	"fmt"
+)
+
+// ClobberingPrefix is prepended by Sanitize to the ids and names that would
+// clobber a DOM property in inline-safe mode
+const ClobberingPrefix = `user-content-`
+
+// domClobberingNames are the window, document and form properties that an
+// element id or name can shadow once the svg is inlined into a html page
+var domClobberingNames = map[string]struct{}{}
+
+func init() {
+	for _, name := range []string{
+		// window
+		`window`, `self`, `top`, `parent`, `frames`, `opener`, `name`, `length`,
+		`location`, `history`, `navigator`, `document`, `origin`, `closed`,
+		`frameElement`, `event`, `external`, `status`, `screen`, `console`,
+		`crypto`, `performance`, `localStorage`, `sessionStorage`, `indexedDB`,
+		`caches`, `customElements`, `isSecureContext`, `trustedTypes`,
+		`alert`, `confirm`, `prompt`, `print`, `fetch`, `open`, `close`,
+		`postMessage`, `setTimeout`, `setInterval`, `clearTimeout`,
+		`clearInterval`, `requestAnimationFrame`, `eval`, `atob`, `btoa`,
+		`Function`, `Object`, `Array`, `String`, `JSON`, `Math`, `Promise`,
+		`Reflect`, `Proxy`, `Symbol`, `XMLHttpRequest`, `WebSocket`, `Worker`,
+		`__proto__`, `constructor`, `prototype`, `toString`, `valueOf`,
+		`hasOwnProperty`, `isPrototypeOf`, `toLocaleString`,
+		// document
+		`activeElement`, `adoptedStyleSheets`, `alinkColor`, `all`, `anchors`,
+		`applets`, `baseURI`, `bgColor`, `body`, `characterSet`, `charset`,
+		`childElementCount`, `childNodes`, `children`, `compatMode`,
+		`contentType`, `cookie`, `currentScript`, `defaultView`, `designMode`,
+		`dir`, `doctype`, `documentElement`, `documentURI`, `domain`, `embeds`,
+		`fgColor`, `firstChild`, `firstElementChild`, `fonts`, `forms`,
+		`fullscreen`, `fullscreenElement`, `fullscreenEnabled`, `head`,
+		`hidden`, `images`, `implementation`, `inputEncoding`, `lastChild`,
+		`lastElementChild`, `lastModified`, `linkColor`, `links`, `nodeName`,
+		`nodeType`, `nodeValue`, `ownerDocument`, `parentElement`,
+		`parentNode`, `plugins`, `readyState`, `referrer`, `rootElement`,
+		`scripts`, `scrollingElement`, `styleSheets`, `textContent`,
+		`timeline`, `title`, `URL`, `visibilityState`, `vlinkColor`,
+		`addEventListener`, `removeEventListener`, `dispatchEvent`,
+		`adoptNode`, `importNode`, `append`, `prepend`, `contains`,
+		`createElement`, `createElementNS`, `createTextNode`, `createRange`,
+		`createEvent`, `elementFromPoint`, `evaluate`, `execCommand`,
+		`getElementById`, `getElementsByClassName`, `getElementsByName`,
+		`getElementsByTagName`, `getSelection`, `hasFocus`, `querySelector`,
+		`querySelectorAll`, `write`, `writeln`, `cloneNode`, `normalize`,
+		// form
+		`action`, `method`, `elements`, `encoding`, `enctype`, `target`,
+		`acceptCharset`, `autocomplete`, `noValidate`, `submit`, `reset`,
+		`requestSubmit`, `checkValidity`, `reportValidity`, `attributes`,
+		`innerHTML`, `outerHTML`, `id`, `className`, `classList`, `style`,
+		`tagName`, `localName`, `namespaceURI`, `ownerElement`,
+	} {
+		domClobberingNames[name] = struct{}{}
+	}
+}
+
+// SetInlineSafe rejects id and name values that would clobber a window,
+// document or form property when the svg is inlined into a html page. In
+// sanitize mode these values, and the references to them, get the
+// ClobberingPrefix instead.
+func (vld *Validator) SetInlineSafe(inlineSafe bool) *Validator {
+	vld.inlineSafe = inlineSafe
+	return vld
+}
+
+func isClobberingName(value string) bool {
+	_, ok := domClobberingNames[value]
+	return ok
+}
+
+func prefixClobberingName(value string) string {
+	if isClobberingName(value) {
+		return ClobberingPrefix + value
+	}
+	return value
+}
+
+// inlineSafe checks an attribute for dom clobbering, rewriting it in sanitize mode
+func (w *walker) inlineSafe(key string, value string) (string, error) {
+	if isIDAttribute(key) || key == `name` {
+		if !isClobberingName(value) {
+			return value, nil
+		}
+		if w.sanitize() {
+			return ClobberingPrefix + value, nil
+		}
+		return value, fmt.Errorf("%w: %s=%q", ErrDOMClobbering, key, value)
+	}
+	if !w.sanitize() {
+		return value, nil
+	}
+	if key == `begin` || key == `end` {
+		return rewriteTimingRefs(value, prefixClobberingName), nil
+	}
+	return rewriteIDRefs(key, value, prefixClobberingName), nil
+}
@@ -0,0 +1,48 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_InlineSafe(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><linearGradient id="cookie"/><g id="forms"/><rect fill="url(#cookie)"/><use xlink:href="#forms"/><g id="icon"/></svg>`)
+	v := NewValidator()
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	v.SetInlineSafe(true)
+	if err := v.Validate(svg); !errors.Is(err, ErrDOMClobbering) {
+		t.Errorf("Expected %v, got %v", ErrDOMClobbering, err)
+	}
+	out, err := v.Sanitize(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><linearGradient id="user-content-cookie"/><g id="user-content-forms"/><rect fill="url(#user-content-cookie)"/><use xlink:href="#user-content-forms"/><g id="icon"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	out, err = v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect id="cookie"><animateTransform attributeName="transform" begin="cookie.click; 1s" end="cookie.end+1s"/></rect></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected = `<svg xmlns="http://www.w3.org/2000/svg"><rect id="user-content-cookie"><animateTransform attributeName="transform" begin="user-content-cookie.click; 1s" end="user-content-cookie.end+1s"/></rect></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	v.WhitelistElements(`style`).SetIDCheck(SeverityError)
+	out, err = v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><style>#cookie,rect{fill:url(#cookie)}</style><linearGradient id="cookie"/><rect/></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected = `<svg xmlns="http://www.w3.org/2000/svg"><style>#user-content-cookie,rect{fill:url(#user-content-cookie)}</style><linearGradient id="user-content-cookie"/><rect/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+}
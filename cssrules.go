@@ -0,0 +1,73 @@
+package safesvg
+
+import (
+	"fmt"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// CSSRules restricts the css of <style> elements and style attributes in
+// addition to ValidateStyle
+type CSSRules struct {
+	// ForbiddenProperties lists the properties that must not be declared,
+	// e.g. "behavior" or "position"
+	ForbiddenProperties []string `json:"forbiddenProperties,omitempty"`
+	// ForbiddenAtRules lists the at-rules that must not be used, e.g.
+	// "@font-face" or "@keyframes"
+	ForbiddenAtRules []string `json:"forbiddenAtRules,omitempty"`
+}
+
+// SetCSSRules restricts the css of <style> elements and style attributes, nil
+// removes the restriction
+func (vld *Validator) SetCSSRules(rules *CSSRules) *Validator {
+	vld.cssRules = rules
+	return vld
+}
+
+// check reports the first forbidden at-rule or property of css, a stylesheet
+// or, if inline, the declarations of a style attribute. css that cannot be
+// tokenized to the end fails, as its rest is not checked.
+func (r *CSSRules) check(css string, inline bool) (err error) {
+	var (
+		property  string
+		candidate = inline // at the start of a declaration
+	)
+	rewriteCSS(css, func(token *scanner.Token, selector bool) string {
+		if err != nil {
+			return ``
+		}
+		declaration := inline || !selector
+		switch token.Type {
+		case scanner.TokenError:
+			err = fmt.Errorf("%w: %s", ErrUnallowedCSSAttributeValue, token.Value)
+		case scanner.TokenAtKeyword:
+			// escapes are resolved, so @font\-face is found as well
+			if containsFold(r.ForbiddenAtRules, cssUnescape(token.Value)) {
+				err = fmt.Errorf("%w: %s", ErrUnallowedCSSAttribute, token.Value)
+			}
+		case scanner.TokenIdent:
+			if candidate && declaration {
+				property = cssUnescape(token.Value)
+			}
+			candidate = false
+		case scanner.TokenS, scanner.TokenComment:
+		case scanner.TokenChar:
+			switch token.Value {
+			case `:`:
+				if len(property) > 0 && containsFold(r.ForbiddenProperties, property) {
+					err = fmt.Errorf("%w: %s", ErrUnallowedCSSAttribute, property)
+				}
+				property = ``
+			case `;`, `{`:
+				candidate = true
+				property = ``
+			default:
+				candidate = false
+			}
+		default:
+			candidate = false
+		}
+		return ``
+	})
+	return
+}
@@ -0,0 +1,73 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+)
+
+// DoctypePolicy decides which DOCTYPE declarations are accepted
+type DoctypePolicy int
+
+const (
+	// DoctypeReject rejects every DOCTYPE declaration
+	DoctypeReject DoctypePolicy = iota
+	// DoctypeAllowSVG accepts the standard SVG 1.0 and 1.1 DOCTYPE
+	// declarations without internal subset
+	DoctypeAllowSVG
+)
+
+// svgDoctypes maps the standard public identifiers to their system identifiers
+var svgDoctypes = map[string]string{
+	`-//W3C//DTD SVG 1.0//EN`:       `http://www.w3.org/TR/2001/REC-SVG-20010904/DTD/svg10.dtd`,
+	`-//W3C//DTD SVG 1.1//EN`:       `http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd`,
+	`-//W3C//DTD SVG 1.1 Basic//EN`: `http://www.w3.org/Graphics/SVG/1.1/DTD/svg11-basic.dtd`,
+	`-//W3C//DTD SVG 1.1 Tiny//EN`:  `http://www.w3.org/Graphics/SVG/1.1/DTD/svg11-tiny.dtd`,
+}
+
+var (
+	entitySystemRegexp = regexp.MustCompile(`(?i)<!ENTITY\b`)
+	doctypeBytes       = []byte(`DOCTYPE`)
+	svgDoctypeRegexp   = regexp.MustCompile(`^DOCTYPE\s+svg\s+PUBLIC\s+(?:"([^"]*)"|'([^']*)')\s+(?:"([^"]*)"|'([^']*)')$`)
+)
+
+// SetDoctypePolicy sets which DOCTYPE declarations are accepted, DoctypeReject by default
+func (vld *Validator) SetDoctypePolicy(policy DoctypePolicy) *Validator {
+	vld.doctypePolicy = policy
+	return vld
+}
+
+// checkDirective checks a <!...> directive and reports whether it must be dropped
+func (w *walker) checkDirective(v xml.Directive) (drop bool, err error) {
+	d := bytes.TrimSpace(v)
+	length := len(d)
+	switch {
+	case length > 8 && bytes.EqualFold(d[0:7], doctypeBytes):
+		if entitySystemRegexp.Match(d) {
+			err = fmt.Errorf("%w: %s", ErrUnallowedEntityAttribute, d)
+		} else if w.doctypeSeen || w.rootSeen || !w.allowDoctype(d) {
+			err = fmt.Errorf("%w: %s", ErrUnallowedDoctype, d)
+		}
+		w.doctypeSeen = true
+	default:
+		err = fmt.Errorf("%w: <!%s>", ErrUnallowedDirective, d)
+	}
+	if err != nil && w.sanitize() {
+		return true, nil
+	}
+	return false, err
+}
+
+func (w *walker) allowDoctype(d []byte) bool {
+	if w.vld.doctypePolicy != DoctypeAllowSVG {
+		return false
+	}
+	m := svgDoctypeRegexp.FindSubmatch(d)
+	if m == nil {
+		return false
+	}
+	public, system := string(m[1])+string(m[2]), string(m[3])+string(m[4])
+	expected, ok := svgDoctypes[public]
+	return ok && expected == system
+}
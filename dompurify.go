@@ -0,0 +1,244 @@
+package safesvg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DOMPurifyConfig holds the options of a DOMPurify configuration object that
+// decide which svg elements and attributes are kept
+type DOMPurifyConfig struct {
+	AllowedTags      []string           `json:"ALLOWED_TAGS"`
+	AllowedAttr      []string           `json:"ALLOWED_ATTR"`
+	AddTags          []string           `json:"ADD_TAGS"`
+	AddAttr          []string           `json:"ADD_ATTR"`
+	ForbidTags       []string           `json:"FORBID_TAGS"`
+	ForbidAttr       []string           `json:"FORBID_ATTR"`
+	UseProfiles      *DOMPurifyProfiles `json:"USE_PROFILES"`
+	AllowedURIRegexp string             `json:"ALLOWED_URI_REGEXP"`
+	AllowDataAttr    *bool              `json:"ALLOW_DATA_ATTR"`
+	AllowARIAAttr    *bool              `json:"ALLOW_ARIA_ATTR"`
+	AddURISafeAttr   []string           `json:"ADD_URI_SAFE_ATTR"`
+	unsupported      []string           // options without an equivalent
+}
+
+// DOMPurifyProfiles is the USE_PROFILES option of DOMPurify
+type DOMPurifyProfiles struct {
+	SVG        bool `json:"svg"`
+	SVGFilters bool `json:"svgFilters"`
+	HTML       bool `json:"html"`
+	MathML     bool `json:"mathMl"`
+}
+
+// dompurifyOutputOptions only change the form of the DOMPurify result, not
+// what is kept
+var dompurifyOutputOptions = map[string]struct{}{
+	`RETURN_DOM`:               {},
+	`RETURN_DOM_FRAGMENT`:      {},
+	`RETURN_TRUSTED_TYPE`:      {},
+	`WHOLE_DOCUMENT`:           {},
+	`IN_PLACE`:                 {},
+	`FORCE_BODY`:               {},
+	`KEEP_CONTENT`:             {},
+	`SANITIZE_DOM`:             {},
+	`SANITIZE_NAMED_PROPS`:     {},
+	`ALLOW_SELF_CLOSE_IN_ATTR`: {},
+}
+
+// dompurifyURISafeAttributes are the attributes whose values DOMPurify does
+// not check against ALLOWED_URI_REGEXP
+var dompurifyURISafeAttributes = []string{
+	`alt`, `class`, `for`, `id`, `label`, `name`, `pattern`, `placeholder`,
+	`role`, `summary`, `title`, `value`, `style`, `xmlns`,
+}
+
+// dompurifyAllowedURI is IS_ALLOWED_URI, the default ALLOWED_URI_REGEXP of
+// DOMPurify
+var dompurifyAllowedURI = regexp.MustCompile(`(?i)^(?:(?:(?:f|ht)tps?|mailto|tel|callto|sms|cid|xmpp|matrix):|[^a-z]|[a-z+.\-]+(?:[^a-z+.\-:]|$))`)
+
+// dompurifyAttrWhitespace is removed from attribute values before DOMPurify
+// matches them against ALLOWED_URI_REGEXP
+var dompurifyAttrWhitespace = regexp.MustCompile(`[\x00-\x20\x{A0}\x{1680}\x{180E}\x{2000}-\x{2029}\x{205F}\x{3000}]`)
+
+// ParseDOMPurifyConfig parses a DOMPurify configuration object in json
+// format. Options without an equivalent, e.g. ALLOW_DATA_ATTR: true or
+// SAFE_FOR_TEMPLATES, are rejected when creating the validator.
+func ParseDOMPurifyConfig(b []byte) (*DOMPurifyConfig, error) {
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal(b, &options); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	cfg := &DOMPurifyConfig{}
+	if err := json.Unmarshal(b, cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	for name := range options {
+		if _, ok := dompurifyOutputOptions[name]; !ok && !isDOMPurifyOption(name) {
+			cfg.unsupported = append(cfg.unsupported, name)
+		}
+	}
+	if cfg.AllowDataAttr != nil && *cfg.AllowDataAttr {
+		cfg.unsupported = append(cfg.unsupported, `ALLOW_DATA_ATTR`)
+	}
+	if cfg.AllowARIAAttr != nil && *cfg.AllowARIAAttr {
+		cfg.unsupported = append(cfg.unsupported, `ALLOW_ARIA_ATTR`)
+	}
+	sort.Strings(cfg.unsupported)
+	return cfg, nil
+}
+
+func isDOMPurifyOption(name string) bool {
+	switch name {
+	case `ALLOWED_TAGS`, `ALLOWED_ATTR`, `ADD_TAGS`, `ADD_ATTR`, `FORBID_TAGS`, `FORBID_ATTR`,
+		`USE_PROFILES`, `ALLOWED_URI_REGEXP`, `ALLOW_DATA_ATTR`, `ALLOW_ARIA_ATTR`, `ADD_URI_SAFE_ATTR`:
+		return true
+	}
+	return false
+}
+
+// NewValidatorFromDOMPurify creates a validator keeping the same svg elements
+// and attributes as DOMPurify with the json configuration object b. Elements
+// and attributes DOMPurify allows but are never safe in a svg file, e.g.
+// <script> and event handlers, stay forbidden. DOMPurify allows data-* and
+// aria-* attributes unless ALLOW_DATA_ATTR and ALLOW_ARIA_ATTR are false;
+// the validator does not, add them with ADD_ATTR. ALLOWED_URI_REGEXP, or
+// DOMPurify's default IS_ALLOWED_URI without it, is checked by attribute
+// value validators, which policy files cannot hold.
+func NewValidatorFromDOMPurify(b []byte) (Validator, error) {
+	cfg, err := ParseDOMPurifyConfig(b)
+	if err != nil {
+		return Validator{}, err
+	}
+	return cfg.Validator()
+}
+
+// Validator creates a validator with the configuration
+func (cfg *DOMPurifyConfig) Validator() (Validator, error) {
+	vld := NewValidator()
+	if len(cfg.unsupported) > 0 {
+		return vld, fmt.Errorf("%w: unsupported DOMPurify option %s", ErrInvalidPolicy, strings.Join(cfg.unsupported, `, `))
+	}
+	// DOMPurify allows every profile by default, the svg ones matter here
+	profiles := &DOMPurifyProfiles{SVG: true, SVGFilters: true}
+	if cfg.UseProfiles != nil {
+		profiles = cfg.UseProfiles
+	}
+	if profiles.HTML || profiles.MathML {
+		return vld, fmt.Errorf("%w: only the svg and svgFilters DOMPurify profiles are supported", ErrInvalidPolicy)
+	}
+	vld.whiteListElements = map[string]string{}
+	vld.whiteListAttributes = map[string]string{}
+	if profiles.SVG {
+		vld.WhitelistElements(mapKeys(svg_elements)...)
+	}
+	if profiles.SVGFilters {
+		vld.WhitelistElements(mapKeys(svg_filters_elements)...)
+	}
+	if profiles.SVG || profiles.SVGFilters {
+		vld.WhitelistAttributes(mapKeys(svg_attributes)...)
+		vld.WhitelistAttributes(mapKeys(xml_attributes)...)
+	}
+	// for the canonical spelling of the names in the configuration
+	elements := canonicalTable(svg_elements, svg_filters_elements)
+	attributes := canonicalTable(svg_attributes, xml_attributes)
+	if cfg.AllowedTags != nil && cfg.UseProfiles == nil {
+		vld.whiteListElements = map[string]string{}
+		vld.WhitelistElements(canonicalNames(elements, cfg.AllowedTags)...)
+	}
+	if cfg.AllowedAttr != nil && cfg.UseProfiles == nil {
+		vld.whiteListAttributes = map[string]string{}
+		vld.WhitelistAttributes(canonicalNames(attributes, cfg.AllowedAttr)...)
+	}
+	vld.WhitelistElements(canonicalNames(elements, cfg.AddTags)...)
+	vld.WhitelistAttributes(canonicalNames(attributes, cfg.AddAttr)...)
+	vld.BlacklistElements(cfg.ForbidTags...)
+	vld.BlacklistAttributes(cfg.ForbidAttr...)
+	allowed := dompurifyAllowedURI
+	if len(cfg.AllowedURIRegexp) > 0 {
+		var err error
+		if allowed, err = compileJSRegexp(cfg.AllowedURIRegexp); err != nil {
+			return vld, fmt.Errorf("%w: ALLOWED_URI_REGEXP: %v", ErrInvalidPolicy, err)
+		}
+	}
+	// like SetAttrValueValidator, the policy cannot be written
+	vld.customValidators = true
+	uriSafe := append(append([]string(nil), dompurifyURISafeAttributes...), cfg.AddURISafeAttr...)
+	for lower := range vld.whiteListAttributes {
+		if containsFold(uriSafe, lower) || strings.HasPrefix(lower, `xmlns:`) {
+			continue
+		}
+		vld.attrValueValidator[lower] = uriValidator(allowed, isHrefAttribute(lower), vld.attrValueValidator[lower])
+	}
+	return vld, nil
+}
+
+func canonicalTable(tables ...map[string]struct{}) map[string]string {
+	canonical := map[string]string{}
+	for _, table := range tables {
+		for name := range table {
+			canonical[strings.ToLower(name)] = name
+		}
+	}
+	return canonical
+}
+
+// canonicalNames returns the canonical spelling of the names, as DOMPurify
+// names are case insensitive, leaving out <script> and event handlers
+func canonicalNames(canonical map[string]string, names []string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if lower == `script` || strings.HasPrefix(lower, `on`) {
+			continue
+		}
+		if c, ok := canonical[lower]; ok {
+			name = c
+		}
+		result = append(result, name)
+	}
+	return result
+}
+
+// uriValidator checks attribute values like DOMPurify with allowed,
+// and with the validator of the attribute, if any. data: urls of href
+// attributes are left to validateHref.
+func uriValidator(allowed *regexp.Regexp, href bool, next func(string) error) func(string) error {
+	if next == nil {
+		next = validateAttrValue
+	}
+	return func(value string) error {
+		if err := next(value); err != nil {
+			return err
+		}
+		stripped := dompurifyAttrWhitespace.ReplaceAllString(value, ``)
+		if len(stripped) > 0 && !allowed.MatchString(stripped) && !(href && strings.HasPrefix(strings.ToLower(stripped), `data:`)) {
+			return fmt.Errorf("%w: %s", ErrUnallowedURL, value)
+		}
+		return nil
+	}
+}
+
+// compileJSRegexp compiles a javascript regular expression, either the
+// pattern or in /pattern/flags form
+func compileJSRegexp(s string) (*regexp.Regexp, error) {
+	if i := strings.LastIndexByte(s, '/'); len(s) > 1 && s[0] == '/' && i > 0 {
+		var flags string
+		for _, flag := range s[i+1:] {
+			switch flag {
+			case 'i', 'm', 's':
+				flags += string(flag)
+			case 'g', 'u', 'y':
+			default:
+				return nil, fmt.Errorf("unknown flag %c", flag)
+			}
+		}
+		s = s[1:i]
+		if len(flags) > 0 {
+			s = `(?` + flags + `)` + s
+		}
+	}
+	return regexp.Compile(s)
+}
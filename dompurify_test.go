@@ -0,0 +1,68 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_DOMPurify(t *testing.T) {
+	v, err := NewValidatorFromDOMPurify([]byte(`{
+		"USE_PROFILES": {"svg": true},
+		"ADD_TAGS": ["fegaussianblur", "script"],
+		"ADD_ATTR": ["data-name", "onclick"],
+		"FORBID_TAGS": ["text"],
+		"FORBID_ATTR": ["opacity"],
+		"ALLOWED_URI_REGEXP": "/^(?:https:|#|[^a-z]|[a-z]+(?:[^a-z:]|$))/i",
+		"RETURN_DOM": false
+	}`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	valid := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><filter id="f"><feGaussianBlur stdDeviation="1"/></filter><rect data-name="a" fill="red"/><image xlink:href="https://example.com/a.png"/></svg>`
+	if err = v.Validate([]byte(valid)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	for svg, expected := range map[string]error{
+		`<svg xmlns="http://www.w3.org/2000/svg"><feFlood/></svg>`:                                                                                ErrInvalidElement,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text/></svg>`:                                                                                   ErrInvalidElement,
+		`<svg xmlns="http://www.w3.org/2000/svg"><script/></svg>`:                                                                                 ErrInvalidElement,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect opacity="1"/></svg>`:                                                                       ErrInvalidAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect onclick="alert(1)"/></svg>`:                                                                ErrInvalidAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="http://example.com/a.png"/></svg>`: ErrUnallowedURL,
+	} {
+		if err = v.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, got %v", svg, expected, err)
+		}
+	}
+
+	if _, err = v.Compile().MarshalJSON(); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Expected %v, got %v", ErrInvalidPolicy, err)
+	}
+
+	// IS_ALLOWED_URI without ALLOWED_URI_REGEXP
+	v, err = NewValidatorFromDOMPurify([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if err = v.Validate([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><a href="https://example.com/"><rect/></a></svg>`)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	for _, href := range []string{`java&#9;script:alert(1)`, `java&#10;script:alert(1)`, `vbscript:msgbox(1)`} {
+		svg := `<svg xmlns="http://www.w3.org/2000/svg"><a href="` + href + `"><rect/></a></svg>`
+		if err = v.Validate([]byte(svg)); !errors.Is(err, ErrUnallowedURL) && !errors.Is(err, ErrUnallowedHrefAttributeValue) {
+			t.Errorf("%s: Expected %v, got %v", svg, ErrUnallowedURL, err)
+		}
+	}
+
+	for _, config := range []string{
+		`{"SAFE_FOR_TEMPLATES": true}`,
+		`{"ALLOW_DATA_ATTR": true}`,
+		`{"USE_PROFILES": {"html": true}}`,
+		`{"ALLOWED_URI_REGEXP": "/(?=a)/"}`,
+		`[]`,
+	} {
+		if _, err = NewValidatorFromDOMPurify([]byte(config)); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: Expected %v, got %v", config, ErrInvalidPolicy, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package safesvg
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// editorNamespaceURIs are the namespaces design tools keep their private
+// data in, by url
+var editorNamespaceURIs = map[string]string{
+	`http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd`: `Inkscape`,
+	`http://www.inkscape.org/namespaces/inkscape`:        `Inkscape`,
+	// the rdf metadata of Inkscape files
+	`http://www.w3.org/1999/02/22-rdf-syntax-ns#`: `Inkscape`,
+	`http://creativecommons.org/ns#`:              `Inkscape`,
+	`http://purl.org/dc/elements/1.1/`:            `Inkscape`,
+	// i:pgf, x:xmpmeta and the other Illustrator namespaces
+	`http://ns.adobe.com/AdobeIllustrator/10.0/`: `Illustrator`,
+	`http://ns.adobe.com/Extensibility/1.0/`:     `Illustrator`,
+	`http://ns.adobe.com/Graphs/1.0/`:            `Illustrator`,
+	`http://ns.adobe.com/Variables/1.0/`:         `Illustrator`,
+	`http://ns.adobe.com/SaveForWeb/1.0/`:        `Illustrator`,
+	`http://ns.adobe.com/ImageReplacement/1.0/`:  `Illustrator`,
+	`adobe:ns:meta/`:                             `Illustrator`,
+	`http://www.bohemiancoding.com/sketch/ns`:    `Sketch`,
+	`https://www.figma.com/figma/ns`:             `Figma`,
+}
+
+// SetEditorNamespaces tolerates the namespaces of Inkscape, Illustrator,
+// Sketch and Figma exports. Their attributes and namespace declarations are
+// ignored when validating and removed when sanitizing, their elements, e.g.
+// <sodipodi:namedview> or <i:pgf>, are rejected when validating and removed
+// with their content when sanitizing.
+func (vld *Validator) SetEditorNamespaces(tolerate bool) *Validator {
+	vld.editorNamespaces = tolerate
+	return vld
+}
+
+// editorElement reports the elements in an editor namespace
+func (w *walker) editorElement(name xml.Name) error {
+	tool, ok := editorNamespaceURIs[name.Space]
+	if !ok {
+		return nil
+	}
+	return fmt.Errorf("%w: %s element %s", ErrInvalidElement, tool, name.Local)
+}
+
+// isEditorAttribute reports whether attr is in an editor namespace or
+// declares one
+func isEditorAttribute(attr xml.Attr) bool {
+	if attr.Name.Space == `xmlns` {
+		_, ok := editorNamespaceURIs[attr.Value]
+		return ok
+	}
+	_, ok := editorNamespaceURIs[attr.Name.Space]
+	return ok
+}
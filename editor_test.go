@@ -0,0 +1,54 @@
+package safesvg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_EditorNamespaces(t *testing.T) {
+	inkscape := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape"` +
+		` xmlns:sodipodi="http://sodipodi.sourceforge.net/DTD/sodipodi-0.dtd" sodipodi:docname="a.svg">` +
+		`<g inkscape:label="Layer 1" inkscape:groupmode="layer"><path d="M0 0h1"/></g></svg>`
+	namedview := strings.Replace(inkscape, `<g `, `<sodipodi:namedview pagecolor="#fff"><inkscape:grid/></sodipodi:namedview><g `, 1)
+	illustrator := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:i="http://ns.adobe.com/AdobeIllustrator/10.0/">` +
+		`<path d="M0 0h1" i:knockout="Off"/><i:pgf id="adobe_illustrator_pgf">SGVsbG8=</i:pgf></svg>`
+
+	for _, parser := range []Parser{ParserEncodingXML, ParserTdewolff} {
+		v := NewValidator()
+		v.SetParser(parser)
+		if err := v.Validate([]byte(inkscape)); !errors.Is(err, ErrInvalidAttribute) {
+			t.Errorf("Expected %v without SetEditorNamespaces, got %v", ErrInvalidAttribute, err)
+		}
+		v.SetEditorNamespaces(true)
+		if err := v.Validate([]byte(inkscape)); err != nil {
+			t.Errorf("Unexptected error %v", err)
+		}
+		for _, svg := range []string{namedview, illustrator} {
+			if err := v.Validate([]byte(svg)); !errors.Is(err, ErrInvalidElement) {
+				t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+			}
+		}
+
+		for svg, expected := range map[string]string{
+			namedview:   `<svg xmlns="http://www.w3.org/2000/svg"><g><path d="M0 0h1"/></g></svg>`,
+			illustrator: `<svg xmlns="http://www.w3.org/2000/svg"><path d="M0 0h1"/></svg>`,
+		} {
+			b, err := v.Sanitize([]byte(svg))
+			if err != nil {
+				t.Fatalf("Unexptected error %v", err)
+			}
+			if string(b) != expected {
+				t.Errorf("Expected %s, got %s", expected, b)
+			}
+		}
+	}
+
+	v, err := NewValidatorProfile(ProfileDesignTool)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if err = v.Validate([]byte(strings.Replace(inkscape, `<path `, `<path mix-blend-mode="multiply" `, 1))); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
@@ -7,7 +7,24 @@ var (
 	ErrInvalidAttribute            = errors.New("[svg] invalid attribute")
 	ErrUnallowedCSSAttributeValue  = errors.New("[svg] unallowed css attribute value")
 	ErrUnallowedCSSAttribute       = errors.New("[svg] unallowed css attribute")
+	ErrUnallowedCSSSelector        = errors.New("[svg] unallowed css selector")
 	ErrUnallowedHrefAttributeValue = errors.New("[svg] unallowed href attribute value")
 	ErrUnallowedEntityAttribute    = errors.New("[svg] unallowed entity attribute")
 	ErrTooManyReferences           = errors.New("[svg] too many references")
+	ErrUnallowedURL                = errors.New("[svg] unallowed url")
+	ErrNetworkReference            = errors.New("[svg] network reference")
+	ErrDuplicateID                 = errors.New("[svg] duplicate id")
+	ErrUndefinedID                 = errors.New("[svg] reference to undefined id")
+	ErrInvalidID                   = errors.New("[svg] invalid id")
+	ErrDOMClobbering               = errors.New("[svg] dom clobbering")
+	ErrInvalidDocument             = errors.New("[svg] invalid document structure")
+	ErrUnallowedDoctype            = errors.New("[svg] unallowed doctype")
+	ErrUnallowedDirective          = errors.New("[svg] unallowed directive")
+	ErrInvalidProcInst             = errors.New("[svg] invalid processing instruction")
+	ErrUnsupportedCharset          = errors.New("[svg] unsupported charset")
+	ErrDecompressionBomb           = errors.New("[svg] decompression limit exceeded")
+	ErrNotSVG                      = errors.New("[svg] not a svg document")
+	ErrPolyglot                    = errors.New("[svg] polyglot file")
+	ErrInvalidPolicy               = errors.New("[svg] invalid policy")
+	ErrNotTinyPS                   = errors.New("[svg] not svg tiny ps")
 )
@@ -5,6 +5,5 @@ go 1.18.0
 require (
 	github.com/gorilla/css v1.0.1
 	github.com/tdewolff/minify/v2 v2.20.37
+	github.com/tdewolff/parse/v2 v2.7.15
 )
-
-require github.com/tdewolff/parse/v2 v2.7.15 // indirect
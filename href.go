@@ -2,24 +2,28 @@ package safesvg
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 )
 
-var hrefDataRegex = regexp.MustCompile(`(?i)^\s*[^/]+/[^/;]+\s*;\s*`)
 var hrefDataMimes = []string{`image/png`, `image/jpg`, `image/jpeg`, `image/pjpeg`, `image/gif`}
 
+// SetFragmentHrefs restricts href and xlink:href to references to the ids
+// of the document, e.g. "#icon", rejecting external and data: urls
+func (vld *Validator) SetFragmentHrefs(only bool) *Validator {
+	vld.fragmentHrefs = only
+	return vld
+}
+
 func validateHref(value string) error {
-	value = strings.TrimSpace(value)
 	if err := validateAttrValue(value); err != nil {
 		return err
 	}
-	if len(value) > 5 && strings.EqualFold(value[0:5], `data:`) { // data:image/png;base64,
-		value2 := value[5:]
-		if !hrefDataRegex.MatchString(value2) {
-			return nil
+	stripped := dompurifyAttrWhitespace.ReplaceAllString(value, ``)
+	if len(stripped) > 5 && strings.EqualFold(stripped[0:5], `data:`) { // data:image/png;base64,
+		mime := stripped[5:]
+		if i := strings.IndexAny(mime, `;,`); i >= 0 {
+			mime = mime[:i]
 		}
-		mime := strings.SplitN(value2, `;`, 2)[0]
 		mime = strings.ToLower(mime)
 		for _, allowed := range hrefDataMimes {
 			if allowed == mime {
@@ -31,16 +35,19 @@ func validateHref(value string) error {
 	return nil
 }
 
+// validateAttrValue rejects javascript: urls. Whitespace and control
+// characters are removed first, like DOMPurify does and browsers do with
+// tabs and newlines in urls, so "java&#9;script:" is rejected as well.
 func validateAttrValue(value string) error {
-	value = strings.TrimSpace(value)
-	length := len(value)
+	stripped := dompurifyAttrWhitespace.ReplaceAllString(value, ``)
+	length := len(stripped)
 	switch {
 	case length > 11:
-		if strings.EqualFold(value[0:11], `javascript:`) {
+		if strings.EqualFold(stripped[0:11], `javascript:`) {
 			return fmt.Errorf(`%w: %s`, ErrUnallowedHrefAttributeValue, value)
 		}
 	case length == 11:
-		if strings.EqualFold(value, `javascript:`) {
+		if strings.EqualFold(stripped, `javascript:`) {
 			return fmt.Errorf(`%w: %s`, ErrUnallowedHrefAttributeValue, value)
 		}
 	}
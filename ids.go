@@ -0,0 +1,190 @@
+package safesvg
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Severity decides how a check reports the problems it finds
+type Severity int
+
+const (
+	// SeverityIgnore disables the check
+	SeverityIgnore Severity = iota
+	// SeverityWarning passes the problems to the warning handler
+	SeverityWarning
+	// SeverityError fails the validation
+	SeverityError
+)
+
+// SetIDCheck reports duplicate ids, ids that are not valid xml names and
+// references to ids that are not defined in the document. In sanitize mode
+// duplicate and invalid ids reported as errors are removed instead.
+func (vld *Validator) SetIDCheck(severity Severity) *Validator {
+	vld.idCheck = severity
+	return vld
+}
+
+// SetWarningHandler sets the function receiving the problems reported with SeverityWarning
+func (vld *Validator) SetWarningHandler(fn func(error)) *Validator {
+	vld.warningHandler = fn
+	return vld
+}
+
+func (vld *Validator) report(severity Severity, err error) error {
+	switch severity {
+	case SeverityWarning:
+		if vld.warningHandler != nil {
+			vld.warningHandler(err)
+		}
+	case SeverityError:
+		return err
+	}
+	return nil
+}
+
+func isIDAttribute(key string) bool {
+	return key == `id` || key == `xml:id`
+}
+
+// checkID reports whether an id attribute must be dropped
+func (w *walker) checkID(id string) (drop bool, err error) {
+	if w.ids == nil {
+		w.ids = map[string]struct{}{}
+	}
+	if !isNCName(id) {
+		err = fmt.Errorf("%w: %q", ErrInvalidID, id)
+	} else if _, ok := w.ids[id]; ok {
+		err = fmt.Errorf("%w: %q", ErrDuplicateID, id)
+	} else {
+		w.ids[id] = struct{}{}
+		return
+	}
+	if w.sanitize() && w.vld.idCheck == SeverityError {
+		return true, nil
+	}
+	return false, w.vld.report(w.vld.idCheck, err)
+}
+
+// addIDRefs records the ids referenced by an attribute value or stylesheet
+func (w *walker) addIDRefs(key string, value string) {
+	if isHrefAttribute(key) {
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, `#`) {
+			w.idRefs = append(w.idRefs, value[1:])
+		}
+		return
+	}
+	for _, sub := range cssURLRegexp.FindAllStringSubmatch(value, -1) {
+		ref := strings.TrimSpace(sub[2])
+		if strings.HasPrefix(ref, `#`) {
+			w.idRefs = append(w.idRefs, ref[1:])
+		}
+	}
+}
+
+// checkIDRefs reports the references to ids missing from the document
+func (w *walker) checkIDRefs() error {
+	reported := map[string]struct{}{}
+	for _, ref := range w.idRefs {
+		if _, ok := w.ids[ref]; ok {
+			continue
+		}
+		if _, ok := reported[ref]; ok {
+			continue
+		}
+		reported[ref] = struct{}{}
+		if err := w.vld.report(w.vld.idCheck, fmt.Errorf("%w: %q", ErrUndefinedID, ref)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isXMLName reports whether s matches the Name production of XML 1.0
+func isXMLName(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for i, r := range s {
+		if r == utf8.RuneError {
+			return false
+		}
+		if i == 0 {
+			if !isNameStartChar(r) {
+				return false
+			}
+		} else if !isNameStartChar(r) && !isNameChar(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNCName reports whether s is an XML name without colon, as required for ids
+func isNCName(s string) bool {
+	return isXMLName(s) && !strings.Contains(s, `:`)
+}
+
+var nameStartChar = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x3A, 0x3A, 1}, // :
+		{0x41, 0x5A, 1},
+		{0x5F, 0x5F, 1}, // _
+		{0x61, 0x7A, 1},
+		{0xC0, 0xD6, 1},
+		{0xD8, 0xF6, 1},
+		{0xF8, 0x2FF, 1},
+		{0x370, 0x37D, 1},
+		{0x37F, 0x1FFF, 1},
+		{0x200C, 0x200D, 1},
+		{0x2070, 0x218F, 1},
+		{0x2C00, 0x2FEF, 1},
+		{0x3001, 0xD7FF, 1},
+		{0xF900, 0xFDCF, 1},
+		{0xFDF0, 0xFFFD, 1},
+	},
+	R32: []unicode.Range32{
+		{0x10000, 0xEFFFF, 1},
+	},
+}
+
+var nameChar = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x2D, 0x2E, 1}, // - .
+		{0x30, 0x39, 1},
+		{0xB7, 0xB7, 1},
+		{0x300, 0x36F, 1},
+		{0x203F, 0x2040, 1},
+	},
+}
+
+func isNameStartChar(r rune) bool {
+	return unicode.Is(nameStartChar, r)
+}
+
+func isNameChar(r rune) bool {
+	return unicode.Is(nameChar, r)
+}
+
+// rewriteIDRefs passes the ids referenced by an attribute value or stylesheet
+// through fn
+func rewriteIDRefs(key string, value string, fn func(id string) string) string {
+	if isHrefAttribute(key) {
+		trimmed := strings.TrimSpace(value)
+		if strings.HasPrefix(trimmed, `#`) {
+			return `#` + fn(trimmed[1:])
+		}
+		return value
+	}
+	return cssURLRegexp.ReplaceAllStringFunc(value, func(m string) string {
+		sub := cssURLRegexp.FindStringSubmatch(m)
+		ref := strings.TrimSpace(sub[2])
+		if !strings.HasPrefix(ref, `#`) {
+			return m
+		}
+		return `url(` + sub[1] + `#` + fn(ref[1:]) + sub[3] + `)`
+	})
+}
@@ -0,0 +1,51 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_IDCheck(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><use xlink:href="#a"/><g id="a"/><g id="a"/><g id="1b"/><rect fill="url(#missing)"/></svg>`)
+	v := NewValidator()
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	var warnings []error
+	v.SetIDCheck(SeverityWarning).SetWarningHandler(func(err error) {
+		warnings = append(warnings, err)
+	})
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	expected := []error{ErrDuplicateID, ErrInvalidID, ErrUndefinedID}
+	if len(warnings) != len(expected) {
+		t.Fatalf("Expected %d warnings, got %v", len(expected), warnings)
+	}
+	for i, err := range expected {
+		if !errors.Is(warnings[i], err) {
+			t.Errorf("Expected %v, got %v", err, warnings[i])
+		}
+	}
+	v.SetIDCheck(SeverityError)
+	if err := v.Validate(svg); !errors.Is(err, ErrDuplicateID) {
+		t.Errorf("Expected %v, got %v", ErrDuplicateID, err)
+	}
+	out, err := v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><g id="a"/><g id="a"/><g id="1b"/></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if string(out) != `<svg xmlns="http://www.w3.org/2000/svg"><g id="a"/><g/><g/></svg>` {
+		t.Errorf("Unexpected output %s", out)
+	}
+	if isXMLName(`a b`) || isXMLName(`-a`) || !isXMLName(`clip0_1:a-b.c`) || !isXMLName(`é`) {
+		t.Errorf("Unexpected xml name check result")
+	}
+	if isNCName(`a:b`) || isNCName(`:a`) || !isNCName(`clip0_1-b.c`) {
+		t.Errorf("Unexpected ncname check result")
+	}
+	v.SetIDCheck(SeverityError)
+	if err := v.Validate([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><g id="a:b"/></svg>`)); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected %v, got %v", ErrInvalidID, err)
+	}
+}
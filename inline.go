@@ -0,0 +1,367 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// presentationAttributes are the css properties with a presentation attribute
+// of the same name and syntax
+var presentationAttributes = map[string]struct{}{
+	`alignment-baseline`: {}, `baseline-shift`: {}, `clip-path`: {}, `clip-rule`: {},
+	`color`: {}, `color-interpolation`: {}, `color-interpolation-filters`: {},
+	`color-rendering`: {}, `display`: {}, `dominant-baseline`: {}, `fill`: {},
+	`fill-opacity`: {}, `fill-rule`: {}, `filter`: {}, `flood-color`: {},
+	`flood-opacity`: {}, `font-family`: {}, `font-size`: {}, `font-stretch`: {},
+	`font-style`: {}, `font-variant`: {}, `font-weight`: {}, `image-rendering`: {},
+	`letter-spacing`: {}, `lighting-color`: {}, `marker-end`: {}, `marker-mid`: {},
+	`marker-start`: {}, `mask`: {}, `opacity`: {}, `overflow`: {}, `paint-order`: {},
+	`shape-rendering`: {}, `stop-color`: {}, `stop-opacity`: {}, `stroke`: {},
+	`stroke-dasharray`: {}, `stroke-dashoffset`: {}, `stroke-linecap`: {},
+	`stroke-linejoin`: {}, `stroke-miterlimit`: {}, `stroke-opacity`: {},
+	`stroke-width`: {}, `text-anchor`: {}, `text-decoration`: {},
+	`text-rendering`: {}, `vector-effect`: {}, `visibility`: {}, `word-spacing`: {},
+}
+
+var (
+	// simpleSelectorRegexp matches a type, class and id selector like rect.a.b#c
+	simpleSelectorRegexp = regexp.MustCompile(`^([A-Za-z][\w-]*)?((?:[.#][A-Za-z_-][\w-]*)*)$`)
+	selectorPartRegexp   = regexp.MustCompile(`[.#][^.#]+`)
+	importantRegexp      = regexp.MustCompile(`(?i)!\s*important`)
+)
+
+// inlineRule is a simple selector with the declarations of its rule
+type inlineRule struct {
+	elem        string
+	id          string
+	classes     []string
+	specificity int
+	decls       [][2]string // property, value
+}
+
+func (r *inlineRule) matches(name string, id string, classes []string) bool {
+	if len(r.elem) > 0 && r.elem != name || len(r.id) > 0 && r.id != id {
+		return false
+	}
+	for _, class := range r.classes {
+		found := false
+		for _, c := range classes {
+			found = found || c == class
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// InlineStyles moves the declarations of the <style> rules with simple type,
+// class and id selectors, e.g. "rect", ".cls-1" or "path.a#b", onto the
+// elements they match, as presentation attributes or in the style attribute,
+// removes the <style> elements and sanitizes the result. It returns the rules
+// that could not be inlined and were dropped: at-rules, selectors with
+// combinators, pseudo-classes or attribute selectors, and !important rules.
+func (vld Validator) InlineStyles(b []byte) (out []byte, skipped []string, err error) {
+	var sheets []string
+	if err = vld.inlineTokens(b, func(to xml.Token, inStyle bool) {
+		switch v := to.(type) {
+		case xml.StartElement:
+			if inStyle {
+				sheets = append(sheets, ``)
+			}
+		case xml.CharData:
+			// the text split by comments and CDATA sections is one stylesheet
+			if inStyle {
+				sheets[len(sheets)-1] += string(v)
+			}
+		}
+	}); err != nil {
+		return
+	}
+	var rules []*inlineRule
+	for _, sheet := range sheets {
+		var sheetSkipped []string
+		rules, sheetSkipped = parseInlineRules(sheet, rules)
+		skipped = append(skipped, sheetSkipped...)
+	}
+	// cascade order: specificity then source order
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].specificity < rules[j].specificity
+	})
+	var buf bytes.Buffer
+	x := newXMLWriter(&buf)
+	if err = vld.inlineTokens(b, func(to xml.Token, inStyle bool) {
+		if inStyle {
+			return
+		}
+		switch v := to.(type) {
+		case xml.StartElement:
+			v.Attr = inlineAttributes(v, rules)
+			x.StartElement(v)
+		case xml.EndElement:
+			x.EndElement(v)
+		case xml.CharData:
+			x.CharData(v)
+		case xml.ProcInst:
+			// the output is UTF-8, whatever the declaration says
+			if !strings.EqualFold(v.Target, `xml`) {
+				x.ProcInst(v)
+			}
+		case xml.Directive:
+			x.Directive(v)
+		}
+	}); err != nil {
+		return
+	}
+	if err = x.Flush(); err != nil {
+		return
+	}
+	out, err = vld.Sanitize(buf.Bytes())
+	return
+}
+
+// inlineTokens passes the tokens of b to fn, with inStyle true for the svg
+// <style> elements and their content, and for <style> elements without
+// namespace unless the root check requires the svg namespace
+func (vld *Validator) inlineTokens(b []byte, fn func(to xml.Token, inStyle bool)) error {
+	w := vld.newWalker(nil)
+	t, err := w.newTokenizer(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	style := 0 // depth in a <style> element
+	for {
+		to, err := t.Token()
+		if err != nil {
+			if err == io.EOF || err.Error() == "EOF" {
+				return nil
+			}
+			return err
+		}
+		switch v := to.(type) {
+		case xml.StartElement:
+			if style > 0 || v.Name.Local == `style` && (v.Name.Space == nsSVG || len(v.Name.Space) == 0 && vld.rootCheck != RootCheckSVGNamespace) {
+				style++
+			}
+			fn(v, style > 0)
+		case xml.EndElement:
+			fn(v, style > 0)
+			if style > 0 {
+				style--
+			}
+		default:
+			fn(to, style > 0)
+		}
+	}
+}
+
+// parseInlineRules appends the inlinable rules of a stylesheet to rules and
+// returns the text of the others
+func parseInlineRules(css string, rules []*inlineRule) ([]*inlineRule, []string) {
+	var (
+		skipped []string
+		prelude strings.Builder
+		block   strings.Builder
+		depth   int
+		atRule  bool
+	)
+	s := scanner.New(css)
+	for {
+		token := s.Next()
+		if token.Type == scanner.TokenEOF || token.Type == scanner.TokenError {
+			break
+		}
+		if depth == 0 {
+			switch {
+			case token.Type == scanner.TokenAtKeyword && prelude.Len() == 0:
+				atRule = true
+			case token.Type == scanner.TokenComment, token.Type == scanner.TokenCDO, token.Type == scanner.TokenCDC:
+				continue
+			case token.Type == scanner.TokenChar && token.Value == `;` && atRule:
+				prelude.WriteString(`;`)
+				skipped = append(skipped, strings.TrimSpace(prelude.String()))
+				prelude.Reset()
+				atRule = false
+				continue
+			case token.Type == scanner.TokenChar && token.Value == `{`:
+				depth++
+				continue
+			}
+			prelude.WriteString(token.Value)
+			continue
+		}
+		switch {
+		case token.Type == scanner.TokenChar && token.Value == `{`:
+			depth++
+		case token.Type == scanner.TokenChar && token.Value == `}`:
+			depth--
+		}
+		if depth > 0 {
+			block.WriteString(token.Value)
+			continue
+		}
+		selectors := strings.TrimSpace(prelude.String())
+		var inlined []*inlineRule
+		if !atRule {
+			inlined = parseInlineRule(selectors, block.String())
+		}
+		if inlined == nil {
+			skipped = append(skipped, selectors+`{`+strings.TrimSpace(block.String())+`}`)
+		}
+		rules = append(rules, inlined...)
+		prelude.Reset()
+		block.Reset()
+		atRule = false
+	}
+	return rules, skipped
+}
+
+// parseInlineRule returns a rule per selector, or nil if any selector is not
+// simple or a declaration is !important
+func parseInlineRule(selectors string, block string) []*inlineRule {
+	var decls [][2]string
+	for _, decl := range splitDeclarations(block) {
+		i := strings.IndexByte(decl, ':')
+		if i < 0 {
+			continue
+		}
+		property := strings.ToLower(strings.TrimSpace(decl[:i]))
+		value := strings.TrimSpace(decl[i+1:])
+		if importantRegexp.MatchString(value) {
+			return nil
+		}
+		decls = append(decls, [2]string{property, value})
+	}
+	var rules []*inlineRule
+	for _, selector := range strings.Split(selectors, `,`) {
+		m := simpleSelectorRegexp.FindStringSubmatch(strings.TrimSpace(selector))
+		if m == nil || len(m[1])+len(m[2]) == 0 {
+			return nil
+		}
+		rule := &inlineRule{elem: m[1], decls: decls}
+		if len(rule.elem) > 0 {
+			rule.specificity = 1
+		}
+		for _, part := range selectorPartRegexp.FindAllString(m[2], -1) {
+			if part[0] == '#' {
+				rule.id = part[1:]
+				rule.specificity += 100
+			} else {
+				rule.classes = append(rule.classes, part[1:])
+				rule.specificity += 10
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// splitDeclarations splits a declaration block at the semicolons outside of
+// strings and urls
+func splitDeclarations(block string) []string {
+	var (
+		decls []string
+		decl  strings.Builder
+	)
+	s := scanner.New(block)
+	for {
+		token := s.Next()
+		if token.Type == scanner.TokenEOF || token.Type == scanner.TokenError {
+			break
+		}
+		if token.Type == scanner.TokenChar && token.Value == `;` {
+			decls = append(decls, decl.String())
+			decl.Reset()
+			continue
+		}
+		decl.WriteString(token.Value)
+	}
+	return append(decls, decl.String())
+}
+
+// inlineAttributes returns the attributes of v with the declarations of the
+// matching rules. The declarations of the style attribute win over the
+// rules, which win over the presentation attributes.
+func inlineAttributes(v xml.StartElement, rules []*inlineRule) []xml.Attr {
+	var (
+		id, style string
+		classes   []string
+	)
+	for _, attr := range v.Attr {
+		if len(attr.Name.Space) > 0 {
+			continue
+		}
+		switch attr.Name.Local {
+		case `id`:
+			id = attr.Value
+		case `class`:
+			classes = strings.Fields(attr.Value)
+		case `style`:
+			style = attr.Value
+		}
+	}
+	var (
+		properties []string
+		values     = map[string]string{}
+	)
+	for _, rule := range rules {
+		if !rule.matches(v.Name.Local, id, classes) {
+			continue
+		}
+		for _, decl := range rule.decls {
+			if _, ok := values[decl[0]]; !ok {
+				properties = append(properties, decl[0])
+			}
+			values[decl[0]] = decl[1]
+		}
+	}
+	if len(properties) == 0 {
+		return v.Attr
+	}
+	inline := map[string]struct{}{}
+	for _, decl := range splitDeclarations(style) {
+		if i := strings.IndexByte(decl, ':'); i >= 0 {
+			inline[strings.ToLower(strings.TrimSpace(decl[:i]))] = struct{}{}
+		}
+	}
+	attrs := append([]xml.Attr(nil), v.Attr...)
+	var styles []string
+	for _, property := range properties {
+		if _, ok := inline[property]; ok {
+			continue
+		}
+		if _, ok := presentationAttributes[property]; !ok {
+			styles = append(styles, property+`:`+values[property])
+			continue
+		}
+		found := false
+		for i, attr := range attrs {
+			if len(attr.Name.Space) == 0 && attr.Name.Local == property {
+				attrs[i].Value = values[property]
+				found = true
+			}
+		}
+		if !found {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: property}, Value: values[property]})
+		}
+	}
+	if len(styles) == 0 {
+		return attrs
+	}
+	if len(strings.TrimSpace(style)) > 0 {
+		styles = append(styles, style)
+	}
+	for i, attr := range attrs {
+		if len(attr.Name.Space) == 0 && attr.Name.Local == `style` {
+			attrs[i].Value = strings.Join(styles, `;`)
+			return attrs
+		}
+	}
+	return append(attrs, xml.Attr{Name: xml.Name{Local: `style`}, Value: strings.Join(styles, `;`)})
+}
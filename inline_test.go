@@ -0,0 +1,56 @@
+package safesvg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_InlineStyles(t *testing.T) {
+	svg := []byte(`<?xml version="1.0" encoding="UTF-8"?><svg xmlns="http://www.w3.org/2000/svg"><style>` +
+		`.cls-1{fill:#f00;mix-blend-mode:multiply}path.cls-1{fill:#0f0}#a{stroke:blue}rect{opacity:.5}` +
+		`g > .cls-1{fill:red}.cls-2:hover{fill:red}@media print{.cls-1{fill:red}}.cls-3{fill:red !important}` +
+		`</style><path class="cls-1" d="M0 0h1" fill="none"/><rect id="a" class="cls-1" style="fill:black"/></svg>`)
+	out, skipped, err := NewValidator().InlineStyles(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg"><path class="cls-1" d="M0 0h1" fill="#0f0" style="mix-blend-mode:multiply"/>` +
+		`<rect id="a" class="cls-1" style="mix-blend-mode:multiply;fill:black" opacity=".5" stroke="blue"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	expectedSkipped := []string{
+		`g > .cls-1{fill:red}`,
+		`.cls-2:hover{fill:red}`,
+		`@media print{.cls-1{fill:red}}`,
+		`.cls-3{fill:red !important}`,
+	}
+	if !reflect.DeepEqual(skipped, expectedSkipped) {
+		t.Errorf("Expected %q, got %q", expectedSkipped, skipped)
+	}
+
+	// type selectors are case sensitive and only svg <style> elements are stylesheets
+	out, _, err = NewValidator().InlineStyles([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><style>lineargradient{color:red}linearGradient{opacity:.5}</style>` +
+		`<x:style xmlns:x="urn:x">rect{fill:red}</x:style><linearGradient/><rect/></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected = `<svg xmlns="http://www.w3.org/2000/svg"><linearGradient opacity=".5"/><rect/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	// without the namespace check, <style> needs no namespace either
+	for _, check := range []RootCheck{RootCheckSVG, RootCheckNone} {
+		v := NewValidator()
+		v.SetRootCheck(check)
+		out, skipped, err = v.InlineStyles([]byte(`<svg><style>.a{fill:red}g .a{fill:blue}</style><rect class="a"/></svg>`))
+		if err != nil {
+			t.Fatalf("Unexptected error %v", err)
+		}
+		expected = `<svg><rect class="a" fill="red"/></svg>`
+		if string(out) != expected || !reflect.DeepEqual(skipped, []string{`g .a{fill:blue}`}) {
+			t.Errorf("%d: Expected %s and skipped rules, got %s %q", check, expected, out, skipped)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Command genwhitelist regenerates the whitelist tables of default.go from
+// the tags.js and attrs.js lists in DOMPurify's src directory, keeping the
+// canonical case of svg names, and reports what changed:
+//
+//	DOMPURIFY_SRC=../DOMPurify/src go generate
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// table is a generated map of names
+type table struct {
+	name   string // go variable
+	doc    string
+	file   string // tags.js or attrs.js
+	export string // DOMPurify list
+}
+
+var tables = []table{
+	{`svg_elements`, `svg_elements are the elements of DOMPurify's svg profile`, `tags.js`, `svg`},
+	{`svg_filters_elements`, `svg_filters_elements are the elements of DOMPurify's svgFilters profile`, `tags.js`, `svgFilters`},
+	{`mathml_elements`, `mathml_elements are the elements of DOMPurify's mathMl profile`, `tags.js`, `mathMl`},
+	{`svg_attributes`, `svg_attributes are the attributes of DOMPurify's svg and svgFilters profiles`, `attrs.js`, `svg`},
+	{`mathml_attributes`, `mathml_attributes are the attributes of DOMPurify's mathMl profile`, `attrs.js`, `mathMl`},
+	{`xml_attributes`, `xml_attributes are the namespaced attributes of every DOMPurify profile`, `attrs.js`, `xml`},
+}
+
+// canonicalNames are the svg names that are not all lowercase. DOMPurify
+// lowercases names, but browsers only know the svg elements and attributes
+// in this spelling.
+var canonicalNames = []string{
+	// elements
+	`altGlyph`, `altGlyphDef`, `altGlyphItem`, `animateColor`, `animateMotion`,
+	`animateTransform`, `clipPath`, `feBlend`, `feColorMatrix`,
+	`feComponentTransfer`, `feComposite`, `feConvolveMatrix`,
+	`feDiffuseLighting`, `feDisplacementMap`, `feDistantLight`, `feDropShadow`,
+	`feFlood`, `feFuncA`, `feFuncB`, `feFuncG`, `feFuncR`, `feGaussianBlur`,
+	`feImage`, `feMerge`, `feMergeNode`, `feMorphology`, `feOffset`,
+	`fePointLight`, `feSpecularLighting`, `feSpotLight`, `feTile`,
+	`feTurbulence`, `foreignObject`, `glyphRef`, `linearGradient`,
+	`radialGradient`, `textPath`,
+	// attributes
+	`attributeName`, `attributeType`, `baseFrequency`, `baseProfile`,
+	`calcMode`, `clipPathUnits`, `diffuseConstant`, `edgeMode`, `filterUnits`,
+	`gradientTransform`, `gradientUnits`, `kernelMatrix`, `kernelUnitLength`,
+	`keyPoints`, `keySplines`, `keyTimes`, `lengthAdjust`, `limitingConeAngle`,
+	`markerHeight`, `markerUnits`, `markerWidth`, `maskContentUnits`,
+	`maskUnits`, `numOctaves`, `pathLength`, `patternContentUnits`,
+	`patternTransform`, `patternUnits`, `pointsAtX`, `pointsAtY`, `pointsAtZ`,
+	`preserveAlpha`, `preserveAspectRatio`, `primitiveUnits`, `refX`, `refY`,
+	`repeatCount`, `repeatDur`, `requiredExtensions`, `requiredFeatures`,
+	`specularConstant`, `specularExponent`, `spreadMethod`, `startOffset`,
+	`stdDeviation`, `stitchTiles`, `surfaceScale`, `systemLanguage`,
+	`tableValues`, `targetX`, `targetY`, `textLength`, `viewBox`, `viewTarget`,
+	`xChannelSelector`, `yChannelSelector`, `zoomAndPan`,
+}
+
+var (
+	exportRegexp = regexp.MustCompile(`(?s)export const (\w+) = freeze\(\[(.*?)\]\)`)
+	stringRegexp = regexp.MustCompile(`'([^']*)'|"([^"]*)"`)
+)
+
+func main() {
+	src := flag.String(`src`, os.Getenv(`DOMPURIFY_SRC`), `DOMPurify's src directory, containing tags.js and attrs.js`)
+	out := flag.String(`out`, `default.go`, `generated file`)
+	flag.Parse()
+	if len(*src) == 0 {
+		log.Fatal(`genwhitelist: set -src or DOMPURIFY_SRC to DOMPurify's src directory`)
+	}
+	generated, err := generate(*src)
+	if err != nil {
+		log.Fatal(`genwhitelist: `, err)
+	}
+	old, err := readTables(*out)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(`genwhitelist: `, err)
+	}
+	if err = os.WriteFile(*out, generated.source, 0o644); err != nil {
+		log.Fatal(`genwhitelist: `, err)
+	}
+	report(os.Stderr, old, generated.tables)
+}
+
+type result struct {
+	source []byte
+	tables map[string][]string
+}
+
+// generate reads the DOMPurify lists and returns the go source of the tables
+func generate(src string) (*result, error) {
+	exports := map[string]map[string][]string{}
+	for _, file := range []string{`tags.js`, `attrs.js`} {
+		b, err := os.ReadFile(filepath.Join(src, file))
+		if err != nil {
+			return nil, err
+		}
+		exports[file] = parseExports(b)
+	}
+	canonical := map[string]string{}
+	for _, name := range canonicalNames {
+		canonical[strings.ToLower(name)] = name
+	}
+	res := &result{tables: map[string][]string{}}
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by genwhitelist from DOMPurify's tags.js and attrs.js. DO NOT EDIT.\n\npackage safesvg\n")
+	for _, t := range tables {
+		list, ok := exports[t.file][t.export]
+		if !ok {
+			return nil, fmt.Errorf(`%s: missing export %s`, t.file, t.export)
+		}
+		var names []string
+		seen := map[string]struct{}{}
+		for _, name := range list {
+			if c, ok := canonical[strings.ToLower(name)]; ok {
+				name = c
+			}
+			if _, ok := seen[name]; ok {
+				continue
+			}
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+		res.tables[t.name] = names
+		fmt.Fprintf(&buf, "\n// %s\nvar %s = map[string]struct{}{\n", t.doc, t.name)
+		for _, name := range names {
+			fmt.Fprintf(&buf, "\t%q: {},\n", name)
+		}
+		buf.WriteString("}\n")
+	}
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	res.source = source
+	return res, nil
+}
+
+// parseExports returns the string lists of `export const name = freeze([...])`
+func parseExports(js []byte) map[string][]string {
+	exports := map[string][]string{}
+	for _, m := range exportRegexp.FindAllSubmatch(js, -1) {
+		var list []string
+		for _, s := range stringRegexp.FindAllSubmatch(m[2], -1) {
+			if s[1] == nil {
+				s[1] = s[2]
+			}
+			list = append(list, string(s[1]))
+		}
+		exports[string(m[1])] = list
+	}
+	return exports
+}
+
+// readTables returns the keys of the map variables of a go file
+func readTables(name string) (map[string][]string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), name, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string][]string{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || len(spec.Values) != 1 {
+			return true
+		}
+		lit, ok := spec.Values[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		var keys []string
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.BasicLit); ok {
+				if s, err := strconv.Unquote(key.Value); err == nil {
+					keys = append(keys, s)
+				}
+			}
+		}
+		tables[spec.Names[0].Name] = keys
+		return false
+	})
+	return tables, nil
+}
+
+// report writes the names added to and removed from each table, then every
+// name of the old tables that is in none of the generated ones
+func report(w io.Writer, old, generated map[string][]string) {
+	var names []string
+	for name := range old {
+		names = append(names, name)
+	}
+	for name := range generated {
+		if _, ok := old[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	tableOf := map[string]string{}
+	for _, t := range tables {
+		for _, n := range generated[t.name] {
+			if _, ok := tableOf[n]; !ok {
+				tableOf[n] = t.name
+			}
+		}
+	}
+	for _, name := range names {
+		added, removed := diff(old[name], generated[name])
+		switch {
+		case generated[name] == nil:
+			fmt.Fprintf(w, "%s: removed table", name)
+			for _, n := range removed {
+				fmt.Fprintf(w, " -%s", n)
+			}
+			fmt.Fprintln(w)
+		case old[name] == nil:
+			fmt.Fprintf(w, "%s: new table with %d names\n", name, len(generated[name]))
+		case len(added) == 0 && len(removed) == 0:
+			fmt.Fprintf(w, "%s: unchanged\n", name)
+		default:
+			fmt.Fprintf(w, "%s:", name)
+			for _, n := range added {
+				fmt.Fprintf(w, " +%s", n)
+			}
+			for _, n := range removed {
+				if moved, ok := tableOf[n]; ok {
+					fmt.Fprintf(w, " -%s (in %s)", n, moved)
+					continue
+				}
+				fmt.Fprintf(w, " -%s", n)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	var gone []string
+	for _, name := range names {
+		for _, n := range old[name] {
+			if _, ok := tableOf[n]; !ok && !contains(gone, n) {
+				gone = append(gone, n)
+			}
+		}
+	}
+	sort.Strings(gone)
+	if len(gone) > 0 {
+		fmt.Fprintf(w, "removed from the default: %s\n", strings.Join(gone, ` `))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func diff(old, generated []string) (added, removed []string) {
+	for _, name := range generated {
+		if !contains(old, name) {
+			added = append(added, name)
+		}
+	}
+	for _, name := range old {
+		if !contains(generated, name) {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return
+}
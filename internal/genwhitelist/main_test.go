@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_Generate(t *testing.T) {
+	res, err := generate(`testdata`)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	for _, expected := range []string{`"viewBox":`, `"clipPath":`, `"feDropShadow":`, `"additive":`, `var mathml_elements`} {
+		if !bytes.Contains(res.source, []byte(expected)) {
+			t.Errorf("Expected %s in the generated source", expected)
+		}
+	}
+	if bytes.Contains(res.source, []byte(`"viewbox"`)) {
+		t.Errorf("Expected canonical case, got viewbox")
+	}
+	var out bytes.Buffer
+	report(&out, map[string][]string{
+		`svg_elements`:   {`svg`, `feBlend`, `use`},
+		`svg_attributes`: res.tables[`svg_attributes`],
+		`xml_attributes`: {`xlink:href`, `baseProfile`},
+	}, map[string][]string{
+		`svg_elements`:         {`svg`, `a`},
+		`svg_filters_elements`: {`feBlend`},
+		`svg_attributes`:       res.tables[`svg_attributes`],
+	})
+	expected := "svg_attributes: unchanged\nsvg_elements: +a -feBlend (in svg_filters_elements) -use\n" +
+		"svg_filters_elements: new table with 1 names\nxml_attributes: removed table -baseProfile -xlink:href\n" +
+		"removed from the default: baseProfile use xlink:href\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+	if !strings.HasPrefix(string(res.source), `// Code generated`) {
+		t.Errorf("Expected a generated code header")
+	}
+}
@@ -0,0 +1,442 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tdewolff/parse/v2"
+	xmllexer "github.com/tdewolff/parse/v2/xml"
+)
+
+// Parser selects the xml tokenizer used by a Validator
+type Parser int
+
+const (
+	// ParserEncodingXML uses xml.Decoder of the standard library
+	ParserEncodingXML Parser = iota
+	// ParserTdewolff uses the xml lexer of github.com/tdewolff/parse, which
+	// reads the whole document into memory but is faster on large batches
+	ParserTdewolff
+)
+
+// SetParser selects the xml tokenizer, ParserEncodingXML by default. Both
+// produce the same tokens, so results can be cross-checked between them.
+func (vld *Validator) SetParser(parser Parser) *Validator {
+	vld.parser = parser
+	return vld
+}
+
+// tokenizer is implemented by xml.Decoder and lexTokenizer
+type tokenizer interface {
+	Token() (xml.Token, error)
+}
+
+var xmlDeclEncodingRegexp = regexp.MustCompile(`^<\?xml\s.*?encoding\s*=\s*["']([^"']+)["']`)
+
+// lexTokenizer turns the tokens of the tdewolff xml lexer into the tokens
+// returned by xml.Decoder.Token, checking well-formedness and translating
+// namespace prefixes along the way. Like with xml.Decoder, the bytes of a
+// token are only valid until the next call to Token.
+type lexTokenizer struct {
+	l       *xmllexer.Lexer
+	line    int
+	stack   []xml.Name          // raw names of the open elements
+	ns      []map[string]string // prefix => namespace url of the open elements, nil without declarations
+	pending xml.EndElement      // end of a self-closing element
+	closing bool                // pending is set
+	attrs   []xml.Attr
+	// the lexer replaces the whitespace of attribute values with spaces in
+	// place, the values are read from a copy of the input instead
+	orig   []byte
+	offset int // in orig of the end of the last token
+}
+
+// newLexTokenizer reads r into memory, converting the charset declared by the
+// xml declaration to UTF-8 with charsetReader
+func newLexTokenizer(r io.Reader, charsetReader func(charset string, input io.Reader) (io.Reader, error)) (*lexTokenizer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if charset := declaredCharset(data); len(charset) > 0 && !strings.EqualFold(charset, `utf-8`) {
+		converted, err := charsetReader(charset, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = io.ReadAll(converted); err != nil {
+			return nil, err
+		}
+	}
+	return &lexTokenizer{
+		l:    xmllexer.NewLexer(parse.NewInputBytes(append([]byte(nil), data...))),
+		line: 1,
+		orig: data,
+	}, nil
+}
+
+// declaredCharset returns the encoding of the xml declaration at the start of data
+func declaredCharset(data []byte) string {
+	if !bytes.HasPrefix(data, []byte(`<?xml`)) {
+		return ``
+	}
+	end := bytes.Index(data, []byte(`?>`))
+	if end < 0 || !bytes.Contains(data[:end], []byte(`encoding`)) {
+		return ``
+	}
+	if m := xmlDeclEncodingRegexp.FindSubmatch(data[:end]); m != nil {
+		return string(m[1])
+	}
+	return ``
+}
+
+func (t *lexTokenizer) syntaxError(format string, args ...interface{}) error {
+	return &xml.SyntaxError{Msg: fmt.Sprintf(format, args...), Line: t.line}
+}
+
+func (t *lexTokenizer) next() (xmllexer.TokenType, []byte) {
+	tt, raw := t.l.Next()
+	t.line += bytes.Count(raw, []byte{'\n'})
+	t.offset += len(raw)
+	return tt, raw
+}
+
+// Token returns the next token like xml.Decoder.Token
+func (t *lexTokenizer) Token() (xml.Token, error) {
+	if t.closing {
+		t.closing = false
+		t.pop()
+		return t.pending, nil
+	}
+	tt, raw := t.next()
+	switch tt {
+	case xmllexer.ErrorToken:
+		err := t.l.Err()
+		if err != io.EOF {
+			return nil, t.syntaxError("%v", err)
+		}
+		if len(t.stack) > 0 {
+			return nil, t.syntaxError("unexpected EOF")
+		}
+		return nil, io.EOF
+	case xmllexer.CommentToken:
+		if len(raw) < len(`<!---->`) || !bytes.HasSuffix(raw, []byte(`-->`)) {
+			return nil, t.syntaxError("unexpected EOF in comment")
+		}
+		// like xml.Decoder, which rejects "--" not followed by '>'
+		if content := raw[4 : len(raw)-3]; bytes.Contains(content, []byte(`--`)) || bytes.HasSuffix(content, []byte(`-`)) {
+			return nil, t.syntaxError(`invalid sequence "--" not allowed in comments`)
+		}
+		return xml.Comment(t.l.Text()), nil
+	case xmllexer.DOCTYPEToken:
+		return xml.Directive(`DOCTYPE` + string(t.l.Text())), nil
+	case xmllexer.CDATAToken:
+		if !bytes.HasSuffix(raw, []byte(`]]>`)) {
+			return nil, t.syntaxError("unexpected EOF in CDATA section")
+		}
+		text, err := t.text(t.l.Text())
+		if err != nil {
+			return nil, err
+		}
+		return t.charData(text)
+	case xmllexer.TextToken:
+		raw := t.l.Text()
+		if bytes.Contains(raw, []byte(`]]>`)) {
+			return nil, t.syntaxError("unescaped ]]> not in CDATA section")
+		}
+		text, err := t.text(raw)
+		if err == nil {
+			text, err = t.unescape(text)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return t.charData(text)
+	case xmllexer.StartTagPIToken:
+		return t.procInst(string(t.l.Text()))
+	case xmllexer.StartTagToken:
+		name := t.l.Text()
+		if len(name) > 0 && name[0] == '!' {
+			return t.directive(string(name[1:]))
+		}
+		return t.startElement(name)
+	case xmllexer.EndTagToken:
+		name := parseName(t.l.Text())
+		if len(t.stack) == 0 {
+			return nil, t.syntaxError("unexpected end element </%s>", t.l.Text())
+		}
+		if open := t.stack[len(t.stack)-1]; open != name {
+			return nil, t.syntaxError("element <%s> closed by </%s>", formatName(open), t.l.Text())
+		}
+		end := xml.EndElement{Name: name}
+		t.translate(&end.Name, true)
+		t.pop()
+		return end, nil
+	}
+	return nil, t.syntaxError("unexpected %s", tt)
+}
+
+func (t *lexTokenizer) charData(text []byte) (xml.Token, error) {
+	if !utf8.Valid(text) {
+		return nil, t.syntaxError("invalid UTF-8")
+	}
+	return xml.CharData(text), nil
+}
+
+// text checks the characters of raw text, CDATA sections and attribute
+// values like xml.Decoder and turns "\r\n" and "\r" into "\n"
+func (t *lexTokenizer) text(raw []byte) ([]byte, error) {
+	for i := 0; i < len(raw); {
+		if c := raw[i]; c < utf8.RuneSelf {
+			if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+				return nil, t.syntaxError("illegal character code %U", rune(c))
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(raw[i:])
+		if r == utf8.RuneError && size == 1 {
+			return nil, t.syntaxError("invalid UTF-8")
+		}
+		if !isXMLChar(r) {
+			return nil, t.syntaxError("illegal character code %U", r)
+		}
+		i += size
+	}
+	if bytes.IndexByte(raw, '\r') >= 0 {
+		raw = bytes.ReplaceAll(raw, []byte("\r\n"), []byte("\n"))
+		raw = bytes.ReplaceAll(raw, []byte("\r"), []byte("\n"))
+	}
+	return raw, nil
+}
+
+func (t *lexTokenizer) pop() {
+	t.stack = t.stack[:len(t.stack)-1]
+	t.ns = t.ns[:len(t.ns)-1]
+}
+
+// rest collects the raw attribute tokens up to the end of the tag
+func (t *lexTokenizer) rest(closing ...xmllexer.TokenType) (xmllexer.TokenType, []byte, error) {
+	var buf []byte
+	for {
+		tt, raw := t.next()
+		for _, c := range closing {
+			if tt == c {
+				return tt, buf, nil
+			}
+		}
+		if tt != xmllexer.AttributeToken {
+			return tt, nil, t.syntaxError("unexpected EOF in tag")
+		}
+		buf = append(buf, raw...)
+	}
+}
+
+func (t *lexTokenizer) procInst(target string) (xml.Token, error) {
+	if len(target) == 0 {
+		return nil, t.syntaxError("expected target name after <?")
+	}
+	_, inst, err := t.rest(xmllexer.StartTagClosePIToken)
+	if err != nil {
+		return nil, err
+	}
+	return xml.ProcInst{Target: target, Inst: bytes.TrimSpace(inst)}, nil
+}
+
+func (t *lexTokenizer) directive(name string) (xml.Token, error) {
+	_, rest, err := t.rest(xmllexer.StartTagCloseToken, xmllexer.StartTagCloseVoidToken)
+	if err != nil {
+		return nil, err
+	}
+	return xml.Directive(name + string(rest)), nil
+}
+
+func (t *lexTokenizer) startElement(rawName []byte) (xml.Token, error) {
+	if !isXMLNameBytes(rawName) {
+		return nil, t.syntaxError("invalid element name %q", rawName)
+	}
+	start := xml.StartElement{Name: parseName(rawName), Attr: t.attrs[:0]}
+	var scope map[string]string
+	for {
+		tt, _ := t.next()
+		switch tt {
+		case xmllexer.AttributeToken:
+			rawAttr := t.l.Text()
+			// the value ends the token
+			val := t.orig[t.offset-len(t.l.AttrVal()) : t.offset]
+			if !isXMLNameBytes(rawAttr) {
+				return nil, t.syntaxError("invalid attribute name %q", rawAttr)
+			}
+			if len(val) < 2 || (val[0] != '"' && val[0] != '\'') || val[len(val)-1] != val[0] {
+				return nil, t.syntaxError("unquoted or missing attribute value in element")
+			}
+			value, err := t.text(val[1 : len(val)-1])
+			if err == nil {
+				value, err = t.unescape(value)
+			}
+			if err != nil {
+				return nil, err
+			}
+			attr := xml.Attr{Name: parseName(rawAttr), Value: string(value)}
+			switch {
+			case attr.Name.Space == `xmlns`:
+				scope = declare(scope, attr.Name.Local, attr.Value)
+			case len(attr.Name.Space) == 0 && attr.Name.Local == `xmlns`:
+				scope = declare(scope, ``, attr.Value)
+			}
+			start.Attr = append(start.Attr, attr)
+		case xmllexer.StartTagCloseToken, xmllexer.StartTagCloseVoidToken:
+			// the attributes are consumed before the next start element
+			t.attrs = start.Attr
+			t.stack = append(t.stack, start.Name)
+			t.ns = append(t.ns, scope)
+			t.translate(&start.Name, true)
+			for i := range start.Attr {
+				t.translate(&start.Attr[i].Name, false)
+			}
+			if tt == xmllexer.StartTagCloseVoidToken {
+				t.pending = xml.EndElement{Name: start.Name}
+				t.closing = true
+			}
+			return start, nil
+		default:
+			return nil, t.syntaxError("unexpected EOF in element <%s>", rawName)
+		}
+	}
+}
+
+func declare(scope map[string]string, prefix, url string) map[string]string {
+	if scope == nil {
+		scope = map[string]string{}
+	}
+	scope[prefix] = url
+	return scope
+}
+
+// translate resolves the namespace prefix of a name like xml.Decoder does
+func (t *lexTokenizer) translate(n *xml.Name, isElementName bool) {
+	switch {
+	case n.Space == `xmlns`:
+		return
+	case len(n.Space) == 0 && !isElementName:
+		return
+	case n.Space == `xml`:
+		n.Space = nsXML
+		return
+	case len(n.Space) == 0 && n.Local == `xmlns`:
+		return
+	}
+	for i := len(t.ns) - 1; i >= 0; i-- {
+		if url, ok := t.ns[i][n.Space]; ok {
+			n.Space = url
+			return
+		}
+	}
+}
+
+func parseName(b []byte) xml.Name {
+	if i := bytes.IndexByte(b, ':'); i > 0 && i < len(b)-1 {
+		return xml.Name{Space: string(b[:i]), Local: string(b[i+1:])}
+	}
+	return xml.Name{Local: string(b)}
+}
+
+func formatName(n xml.Name) string {
+	if len(n.Space) > 0 {
+		return n.Space + `:` + n.Local
+	}
+	return n.Local
+}
+
+var xmlEntities = map[string]string{
+	`lt`:   `<`,
+	`gt`:   `>`,
+	`amp`:  `&`,
+	`apos`: `'`,
+	`quot`: `"`,
+}
+
+// unescape replaces the entity and character references of text, which
+// must not contain a '<' or undefined entity
+func (t *lexTokenizer) unescape(text []byte) ([]byte, error) {
+	if bytes.IndexByte(text, '<') >= 0 {
+		return nil, t.syntaxError("unescaped < inside quoted string")
+	}
+	if bytes.IndexByte(text, '&') < 0 {
+		return text, nil
+	}
+	var buf []byte
+	for len(text) > 0 {
+		i := bytes.IndexByte(text, '&')
+		if i < 0 {
+			buf = append(buf, text...)
+			break
+		}
+		buf = append(buf, text[:i]...)
+		text = text[i+1:]
+		end := bytes.IndexByte(text, ';')
+		if end <= 0 {
+			return nil, t.syntaxError("invalid character entity &%.10s", text)
+		}
+		name := string(text[:end])
+		text = text[end+1:]
+		if s, ok := xmlEntities[name]; ok {
+			buf = append(buf, s...)
+			continue
+		}
+		if name[0] != '#' {
+			return nil, t.syntaxError("invalid character entity &%s;", name)
+		}
+		var (
+			n   uint64
+			err error
+		)
+		if len(name) > 1 && name[1] == 'x' {
+			n, err = strconv.ParseUint(name[2:], 16, 32)
+		} else {
+			n, err = strconv.ParseUint(name[1:], 10, 32)
+		}
+		if err != nil || n > unicode.MaxRune {
+			return nil, t.syntaxError("invalid character entity &%s;", name)
+		}
+		r := rune(n)
+		if r >= 0xD800 && r <= 0xDFFF {
+			// xml.Decoder turns surrogates into U+FFFD
+			r = utf8.RuneError
+		}
+		if !isXMLChar(r) {
+			return nil, t.syntaxError("illegal character code %U", r)
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return buf, nil
+}
+
+// isXMLNameBytes is isXMLName for a name that is mostly ASCII
+func isXMLNameBytes(b []byte) bool {
+	for i, c := range b {
+		switch {
+		case c >= utf8.RuneSelf:
+			return isXMLName(string(b))
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_', c == ':':
+		case i > 0 && (c >= '0' && c <= '9' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+// isXMLChar reports whether r matches the Char production of XML 1.0
+func isXMLChar(r rune) bool {
+	return r == 0x09 || r == 0x0A || r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
@@ -0,0 +1,72 @@
+package safesvg
+
+import (
+	"testing"
+)
+
+func Test_ParserCrossCheck(t *testing.T) {
+	docs := []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><g id="a"><path d="M0 0h24"/></g><use xlink:href="#a"/></svg>`,
+		`<?xml version="1.0" encoding="ISO-8859-1"?>` + "\n<svg xmlns=\"http://www.w3.org/2000/svg\"><text>caf\xe9</text></svg>",
+		`<svg xmlns="http://www.w3.org/2000/svg"><text x='1'>a &lt; b &#x26; &#99;</text><!-- comment --><style><![CDATA[rect{fill:red}]]></style></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><script>alert(1)</script></svg>`,
+		`<!DOCTYPE svg [<!ENTITY x "y">]><svg xmlns="http://www.w3.org/2000/svg"/>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><g></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text>&nbsp;</text></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg" width=24></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><g>`,
+		`<html xmlns="http://www.w3.org/1999/xhtml"/>`,
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:x="http://example.com/x"><x:g/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><!-- a -- b --></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><!-- a ---></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><!----><!---a--></svg>`,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><rect id=\"\xff\"/></svg>",
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><text>\x01</text></svg>",
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><text font-family=\"\x01\">a</text></svg>",
+		`<svg xmlns="http://www.w3.org/2000/svg"><text>a ]]> b</text></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text font-family="&#xD800;">&#xD800;</text></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><text>&#1;</text></svg>`,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><text font-family=\"a\r\nb\">a\r\nb\rc&#13;</text></svg>",
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><style><![CDATA[a\r\nb]]></style></svg>",
+	}
+	for _, doc := range docs {
+		std := NewValidator()
+		lex := NewValidator()
+		lex.SetParser(ParserTdewolff)
+		stdErr := std.Validate([]byte(doc))
+		lexErr := lex.Validate([]byte(doc))
+		if (stdErr == nil) != (lexErr == nil) {
+			t.Errorf("%s: encoding/xml error %v, tdewolff error %v", doc, stdErr, lexErr)
+		}
+		stdOut, stdErr := std.Sanitize([]byte(doc))
+		lexOut, lexErr := lex.Sanitize([]byte(doc))
+		if (stdErr == nil) != (lexErr == nil) || string(stdOut) != string(lexOut) {
+			t.Errorf("%s: encoding/xml sanitized %s (%v), tdewolff sanitized %s (%v)", doc, stdOut, stdErr, lexOut, lexErr)
+		}
+	}
+}
+
+func Test_ParserConcurrent(t *testing.T) {
+	valid := NewValidator()
+	valid.SetParser(ParserTdewolff)
+	invalid := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><g id="a"/><g id="a"/><script/></svg>`)
+	done := make(chan error)
+	for i := 0; i < 8; i++ {
+		go func(i int) {
+			var err error
+			for j := 0; j < 50 && err == nil; j++ {
+				if i%2 == 0 {
+					err = valid.Validate(benchSVG)
+				} else if valid.Validate(invalid) == nil {
+					err = ErrInvalidElement
+				}
+			}
+			done <- err
+		}(i)
+	}
+	for i := 0; i < 8; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Unexptected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package safesvg
+
+import (
+	"strings"
+)
+
+// canonicalSpellings maps the lowercase spelling of the mixed-case default svg
+// names to their canonical spelling
+var canonicalSpellings = func() map[string]string {
+	names := map[string]string{}
+	var add func(name string)
+	add = func(name string) {
+		if lower := strings.ToLower(name); lower != name {
+			names[lower] = name
+		}
+		if i := strings.IndexByte(name, ':'); i > 0 {
+			add(name[i+1:])
+		}
+	}
+	for _, table := range []map[string]struct{}{
+		svg_elements, svg_filters_elements, svg_attributes, xml_attributes,
+	} {
+		for name := range table {
+			add(name)
+		}
+	}
+	for _, name := range append(append([]string{}, defaultExtraElements...), defaultExtraAttributes...) {
+		add(name)
+	}
+	return names
+}()
+
+// addName adds name to a whitelist of canonical spellings by lowercase
+// spelling. A lowercase name does not replace a canonical spelling, so that
+// e.g. "lineargradient" keeps matching <linearGradient> in strict case mode.
+func addName(names map[string]string, name string) {
+	lower := strings.ToLower(name)
+	if name == lower {
+		if _, ok := names[lower]; ok {
+			return
+		}
+		if canonical, ok := canonicalSpellings[lower]; ok {
+			name = canonical
+		}
+	}
+	names[lower] = name
+}
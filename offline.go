@@ -0,0 +1,202 @@
+package safesvg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// networkElements are the elements whose only purpose is loading a resource
+var networkElements = map[string]struct{}{
+	"feimage":       {},
+	"cursor":        {},
+	"color-profile": {},
+}
+
+// SetOffline forbids every construct that can make the renderer fetch a
+// resource: href and xlink:href other than fragments and data: urls, url(...)
+// and image-set("...") in attribute values and stylesheets, also when written
+// with css escapes, @import, @font-face src, the feImage, cursor and
+// color-profile elements and xml-stylesheet processing instructions
+func (vld *Validator) SetOffline(offline bool) *Validator {
+	vld.offline = offline
+	return vld
+}
+
+// attrNetworkReferences returns the urls an attribute value would fetch
+func attrNetworkReferences(key string, value string) []string {
+	if isHrefAttribute(key) {
+		if isLocalURL(value) {
+			return nil
+		}
+		return []string{key + `="` + strings.TrimSpace(value) + `"`}
+	}
+	if !strings.ContainsAny(value, `(`) {
+		return nil
+	}
+	refs := cssNetworkReferences([]byte(value))
+	for i, ref := range refs {
+		refs[i] = key + ` ` + ref
+	}
+	return refs
+}
+
+// cssImageFunctions take image urls as strings, e.g. image-set("a.png" 1x)
+var cssImageFunctions = map[string]struct{}{
+	`image-set`:         {},
+	`-webkit-image-set`: {},
+	`image`:             {},
+	`cross-fade`:        {},
+}
+
+// cssNetworkReferences returns the @import rules and urls a stylesheet or a
+// css attribute value would fetch, and "unreadable css" if it cannot be
+// tokenized to the end
+func cssNetworkReferences(css []byte) []string {
+	var refs []string
+	err := cssReferences(string(css), func(atRule string, u string) {
+		switch {
+		case atRule == `@import`:
+			refs = append(refs, `@import`)
+		case isLocalURL(u):
+		case atRule == `@font-face`:
+			refs = append(refs, `@font-face src url(`+strings.TrimSpace(u)+`)`)
+		default:
+			refs = append(refs, `url(`+strings.TrimSpace(u)+`)`)
+		}
+	})
+	if err != nil {
+		// the text after the error may hold urls the browser fetches
+		refs = append(refs, `unreadable css`)
+	}
+	return refs
+}
+
+// cssReferences passes the urls of a stylesheet or css attribute value to fn,
+// with atRule "@font-face" for the urls of @font-face rules, and calls it with
+// atRule "@import" and no url for @import rules. Escapes are resolved before
+// matching, so u\72l(...) is found as well as url(...). It returns an error
+// if the tokenizer fails, e.g. on an unclosed string, as the text after it is
+// not searched while browsers recover and apply it.
+func cssReferences(css string, fn func(atRule string, u string)) error {
+	var (
+		fontFace  bool
+		depth     int
+		functions []string // open functions, unescaped and lowercased
+		arg       strings.Builder
+	)
+	add := func(u string) {
+		if fontFace {
+			fn(`@font-face`, u)
+			return
+		}
+		fn(``, u)
+	}
+	s := scanner.New(css)
+	for {
+		token := s.Next()
+		switch token.Type {
+		case scanner.TokenEOF:
+			return nil
+		case scanner.TokenError:
+			return fmt.Errorf("%w: %s", ErrUnallowedCSSAttributeValue, token.Value)
+		}
+		function := ``
+		if len(functions) > 0 {
+			function = functions[len(functions)-1]
+		}
+		if (function == `url` || function == `src`) && !(token.Type == scanner.TokenChar && token.Value == `)`) {
+			// the url of url( with escapes or src(
+			if token.Type == scanner.TokenString {
+				arg.WriteString(cssUnescape(token.Value[1 : len(token.Value)-1]))
+			} else if token.Type != scanner.TokenS {
+				arg.WriteString(cssUnescape(token.Value))
+			}
+			continue
+		}
+		switch token.Type {
+		case scanner.TokenAtKeyword:
+			switch strings.ToLower(cssUnescape(token.Value)) {
+			case `@import`:
+				fn(`@import`, ``)
+			case `@font-face`:
+				fontFace = true
+			}
+		case scanner.TokenFunction:
+			functions = append(functions, strings.ToLower(cssUnescape(strings.TrimSuffix(token.Value, `(`))))
+			arg.Reset()
+		case scanner.TokenChar:
+			switch token.Value {
+			case `(`:
+				functions = append(functions, ``)
+			case `)`:
+				if len(functions) > 0 {
+					functions = functions[:len(functions)-1]
+				}
+				if function == `url` || function == `src` {
+					add(arg.String())
+				}
+			case `{`:
+				depth++
+			case `}`:
+				depth--
+				if depth <= 0 {
+					fontFace = false
+				}
+			}
+		case scanner.TokenString:
+			if _, ok := cssImageFunctions[function]; ok {
+				add(cssUnescape(token.Value[1 : len(token.Value)-1]))
+			}
+		case scanner.TokenURI:
+			sub := cssURLRegexp.FindStringSubmatch(token.Value)
+			if sub == nil {
+				// url( written with escapes inside the parentheses
+				add(cssUnescape(strings.TrimSuffix(token.Value[4:], `)`)))
+				continue
+			}
+			add(cssUnescape(sub[2]))
+		}
+	}
+}
+
+// cssUnescape resolves the css escapes of s: a backslash followed by up to
+// six hex digits and an optional whitespace, or by any other character
+func cssUnescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		j := i
+		for j < len(s) && j-i < 6 && isHexDigit(s[j]) {
+			j++
+		}
+		if j == i {
+			b.WriteByte(s[i])
+			continue
+		}
+		r, _ := strconv.ParseUint(s[i:j], 16, 32)
+		if r == 0 || r > unicode.MaxRune || r >= 0xD800 && r <= 0xDFFF {
+			r = unicode.ReplacementChar
+		}
+		b.WriteRune(rune(r))
+		if j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\n') {
+			j++
+		}
+		i = j - 1
+	}
+	return b.String()
+}
+
+func isHexDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
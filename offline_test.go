@@ -0,0 +1,76 @@
+package safesvg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Offline(t *testing.T) {
+	svg := []byte(`<?xml version="1.0"?>
+<?xml-stylesheet href="https://evil.example/a.css"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><style>@import "a.css";@font-face{font-family:a;src:url(https://evil.example/a.woff)}.a{fill:url(#g)}</style><filter><feImage xlink:href="https://evil.example/a.png"/></filter><image xlink:href="https://evil.example/pixel.png"/><image xlink:href="data:image/png;base64,AA"/><rect style="cursor:url(cur.cur),auto" fill="url(#g)"/><use xlink:href="#a"/></svg>`)
+	v := NewValidator()
+	v.WhitelistElements(`style`)
+	if err := v.Validate(svg); errors.Is(err, ErrNetworkReference) {
+		t.Errorf("Unexptected error %v", err)
+	}
+	v.SetOffline(true)
+	err := v.Validate(svg)
+	if !errors.Is(err, ErrNetworkReference) {
+		t.Fatalf("Expected %v, got %v", ErrNetworkReference, err)
+	}
+	for _, ref := range []string{`xml-stylesheet`, `@import`, `@font-face src`, `feImage`, `pixel.png`, `rect style url(cur.cur)`} {
+		if !strings.Contains(err.Error(), ref) {
+			t.Errorf("Expected %q to be listed in %v", ref, err)
+		}
+	}
+	out, err := v.Sanitize(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v in %s", err, out)
+	}
+	if !strings.Contains(string(out), `data:image/png`) || !strings.Contains(string(out), `fill="url(#g)"`) {
+		t.Errorf("Expected local references to be kept: %s", out)
+	}
+}
+
+func Test_OfflineEscapes(t *testing.T) {
+	v := NewValidator()
+	v.SetOffline(true)
+	for _, style := range []string{
+		`fill:u\72l(http://evil.example/x)`,
+		`fill:\75 rl(http://evil.example/x)`,
+		`fill:url(http\3a //evil.example/x)`,
+		`fill:url( 'http://evil.example/x' )`,
+		`fill:URL("http://evil.example/x")`,
+		`background:image-set("http://evil.example/x" 1x)`,
+		`background:-webkit-image-set('http://evil.example/x' 1x)`,
+	} {
+		svg := `<svg xmlns="http://www.w3.org/2000/svg"><rect style="` + strings.ReplaceAll(style, `"`, `&quot;`) + `"/></svg>`
+		err := v.Validate([]byte(svg))
+		if !errors.Is(err, ErrNetworkReference) || !strings.Contains(err.Error(), `url(http://evil.example/x)`) {
+			t.Errorf("%s: Expected %v, got %v", style, ErrNetworkReference, err)
+		}
+	}
+	for _, style := range []string{`fill:url(#g)`, `fill:u\72l(#g)`, `background:image-set("data:image/png;base64,AA" 1x)`} {
+		if refs := attrNetworkReferences(`style`, style); len(refs) > 0 {
+			t.Errorf("%s: Unexpected references %v", style, refs)
+		}
+	}
+
+	v.WhitelistElements(`style`)
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="a:&quot;x&#10;;fill:url(https://evil.example/t.png)"/></svg>`,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><style>.a{font-family:\"x\n}.b{fill:url(https://evil.example/t.png)}</style></svg>",
+	} {
+		if err := v.Validate([]byte(svg)); !errors.Is(err, ErrNetworkReference) || !strings.Contains(err.Error(), `unreadable css`) {
+			t.Errorf("%s: Expected %v, got %v", svg, ErrNetworkReference, err)
+		}
+		if out, err := v.Sanitize([]byte(svg)); err != nil || strings.Contains(string(out), `evil`) {
+			t.Errorf("%s: Expected the reference to be dropped, got %s (%v)", svg, out, err)
+		}
+	}
+}
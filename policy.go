@@ -0,0 +1,93 @@
+package safesvg
+
+import (
+	"io"
+)
+
+// Policy is a compiled Validator. It cannot be changed after Compile, so
+// unlike a Validator it is safe to share between goroutines.
+type Policy struct {
+	vld Validator
+}
+
+// Clone returns a deep copy of the validator. Copies of a Validator value
+// share its whitelists, changing one changes all of them; clones do not.
+func (vld Validator) Clone() Validator {
+	clone := vld
+	clone.whiteListElements = cloneMap(vld.whiteListElements)
+	clone.whiteListAttributes = cloneMap(vld.whiteListAttributes)
+	clone.elementAttributes = make(map[string]map[string]string, len(vld.elementAttributes))
+	for elem, allowed := range vld.elementAttributes {
+		clone.elementAttributes[elem] = cloneMap(allowed)
+	}
+	clone.innerTextValidator = cloneMap(vld.innerTextValidator)
+	clone.attrValueValidator = cloneMap(vld.attrValueValidator)
+	if vld.urlPolicy != nil {
+		policy := *vld.urlPolicy
+		policy.Schemes = append([]string(nil), policy.Schemes...)
+		policy.Hosts = append([]string(nil), policy.Hosts...)
+		clone.urlPolicy = &policy
+	}
+	if vld.cssRules != nil {
+		rules := *vld.cssRules
+		rules.ForbiddenProperties = append([]string(nil), rules.ForbiddenProperties...)
+		rules.ForbiddenAtRules = append([]string(nil), rules.ForbiddenAtRules...)
+		clone.cssRules = &rules
+	}
+	if vld.cssScope != nil {
+		scope := *vld.cssScope
+		clone.cssScope = &scope
+	}
+	return clone
+}
+
+// Compile returns an immutable Policy with the current configuration of the
+// validator. Later changes to the validator do not affect the policy.
+func (vld Validator) Compile() *Policy {
+	return &Policy{vld: vld.Clone()}
+}
+
+// Validator returns a validator with the configuration of the policy, to
+// derive another policy from
+func (p *Policy) Validator() Validator {
+	return p.vld.Clone()
+}
+
+// Validate validates a slice of bytes containing the svg data
+func (p *Policy) Validate(b []byte) error {
+	return p.vld.Validate(b)
+}
+
+// ValidateReader validates svg data from an io.Reader interface
+func (p *Policy) ValidateReader(r io.Reader) error {
+	return p.vld.ValidateReader(r)
+}
+
+// ValidateFile validates the svg or svgz file with the given name
+func (p *Policy) ValidateFile(name string) error {
+	return p.vld.ValidateFile(name)
+}
+
+// Sanitize returns the svg data with every element, attribute and text that
+// fails validation removed
+func (p *Policy) Sanitize(b []byte) ([]byte, error) {
+	return p.vld.Sanitize(b)
+}
+
+// SanitizeReader sanitizes svg data from an io.Reader and writes the result to w
+func (p *Policy) SanitizeReader(w io.Writer, r io.Reader) error {
+	return p.vld.SanitizeReader(w, r)
+}
+
+// ValidateBIMI checks that b is a BIMI logo in SVG Tiny PS, see Validator.ValidateBIMI
+func (p *Policy) ValidateBIMI(b []byte) error {
+	return p.vld.ValidateBIMI(b)
+}
+
+func cloneMap[V any](m map[string]V) map[string]V {
+	clone := make(map[string]V, len(m))
+	for k, v := range m {
+		clone[k] = v
+	}
+	return clone
+}
@@ -0,0 +1,48 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Policy(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><circle r="1"/></svg>`)
+	v := NewValidator()
+	policy := v.Compile()
+	v.BlacklistElements(`circle`)
+	if err := policy.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	if err := v.Validate(svg); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+	derived := policy.Validator()
+	derived.BlacklistElements(`circle`)
+	if err := policy.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	clone := v.Clone()
+	clone.WhitelistElements(`circle`)
+	if err := v.Validate(svg); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+	if err := clone.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	done := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			var err error
+			for j := 0; j < 50 && err == nil; j++ {
+				err = policy.Validate(svg)
+			}
+			done <- err
+		}()
+	}
+	clone.BlacklistElements(`circle`)
+	for i := 0; i < 4; i++ {
+		if err := <-done; err != nil {
+			t.Errorf("Unexptected error %v", err)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package safesvg
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// PolicyVersion is the version of the policy file format
+const PolicyVersion = 1
+
+// policyFile is the json format of a Policy. The RewriteURL function of the
+// url policy, the ID function of the css scope and the warning handler are
+// code and not part of it. Custom validators are code too, a Policy with them
+// cannot be written.
+type policyFile struct {
+	Version            int                 `json:"version"`
+	Elements           []string            `json:"elements"`
+	Attributes         []string            `json:"attributes"`
+	ElementAttributes  map[string][]string `json:"elementAttributes,omitempty"`
+	CSS                *CSSRules           `json:"css,omitempty"`
+	URLs               *policyURLs         `json:"urls,omitempty"`
+	Limits             *policyLimits       `json:"limits,omitempty"`
+	Offline            bool                `json:"offline,omitempty"`
+	IDCheck            string              `json:"idCheck,omitempty"`
+	InlineSafe         bool                `json:"inlineSafe,omitempty"`
+	IDPrefix           string              `json:"idPrefix,omitempty"`
+	RootCheck          string              `json:"rootCheck,omitempty"`
+	Doctype            string              `json:"doctype,omitempty"`
+	AllowXMLStylesheet bool                `json:"allowXMLStylesheet,omitempty"`
+	RequireUTF8        bool                `json:"requireUTF8,omitempty"`
+	StrictCase         bool                `json:"strictCase,omitempty"`
+	Parser             string              `json:"parser,omitempty"`
+	EditorNamespaces   bool                `json:"editorNamespaces,omitempty"`
+	AllowStyle         bool                `json:"allowStyle,omitempty"`
+	CSSScope           *CSSScope           `json:"cssScope,omitempty"`
+	FragmentHrefs      bool                `json:"fragmentHrefs,omitempty"`
+}
+
+type policyURLs struct {
+	Schemes       []string `json:"schemes"`
+	Hosts         []string `json:"hosts,omitempty"`
+	AllowRelative bool     `json:"allowRelative,omitempty"`
+}
+
+type policyLimits struct {
+	MaxDecompressedSize int64 `json:"maxDecompressedSize,omitempty"`
+	MaxCompressionRatio int64 `json:"maxCompressionRatio,omitempty"`
+}
+
+// the names of the enum values in policy files, by value
+var (
+	severityNames  = []string{`ignore`, `warning`, `error`}
+	rootCheckNames = []string{`svg-namespace`, `svg`, `none`}
+	doctypeNames   = []string{`reject`, `allow-svg`}
+	parserNames    = []string{`encoding/xml`, `tdewolff`}
+)
+
+// LoadPolicy reads a json policy file, as written by Policy.MarshalJSON.
+// Unknown fields, unknown values and invalid names are rejected.
+func LoadPolicy(r io.Reader) (*Policy, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var file policyFile
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("%w: data after the policy", ErrInvalidPolicy)
+	}
+	vld, err := file.validator()
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{vld: vld}, nil
+}
+
+func (file *policyFile) validator() (Validator, error) {
+	vld := NewValidator()
+	vld.whiteListElements = map[string]string{}
+	vld.whiteListAttributes = map[string]string{}
+	switch {
+	case file.Version != PolicyVersion:
+		return vld, fmt.Errorf("%w: unsupported version %d", ErrInvalidPolicy, file.Version)
+	case file.Elements == nil:
+		return vld, fmt.Errorf("%w: missing elements", ErrInvalidPolicy)
+	}
+	if err := checkPolicyNames(`elements`, file.Elements); err != nil {
+		return vld, err
+	}
+	if err := checkPolicyNames(`attributes`, file.Attributes); err != nil {
+		return vld, err
+	}
+	vld.WhitelistElements(file.Elements...)
+	vld.WhitelistAttributes(file.Attributes...)
+	for elem, attrs := range file.ElementAttributes {
+		if err := checkPolicyNames(`elementAttributes`, append([]string{elem}, attrs...)); err != nil {
+			return vld, err
+		}
+		vld.WhitelistElementAttributes(elem, attrs...)
+	}
+	if file.CSS != nil {
+		for _, property := range file.CSS.ForbiddenProperties {
+			if len(property) == 0 || strings.ContainsAny(property, " :;{}") {
+				return vld, fmt.Errorf("%w: invalid css property %q", ErrInvalidPolicy, property)
+			}
+		}
+		for _, atRule := range file.CSS.ForbiddenAtRules {
+			if len(atRule) < 2 || atRule[0] != '@' || strings.ContainsAny(atRule, " :;{}") {
+				return vld, fmt.Errorf("%w: invalid css at-rule %q", ErrInvalidPolicy, atRule)
+			}
+		}
+		vld.SetCSSRules(file.CSS)
+	}
+	if file.URLs != nil {
+		for _, scheme := range file.URLs.Schemes {
+			if len(scheme) == 0 || strings.ContainsAny(scheme, ":/ ") {
+				return vld, fmt.Errorf("%w: invalid url scheme %q", ErrInvalidPolicy, scheme)
+			}
+		}
+		for _, host := range file.URLs.Hosts {
+			if len(host) == 0 || strings.ContainsAny(host, ":/ ") {
+				return vld, fmt.Errorf("%w: invalid url host %q", ErrInvalidPolicy, host)
+			}
+		}
+		vld.SetURLPolicy(&URLPolicy{
+			Schemes:       file.URLs.Schemes,
+			Hosts:         file.URLs.Hosts,
+			AllowRelative: file.URLs.AllowRelative,
+		})
+	}
+	if file.Limits != nil {
+		if file.Limits.MaxDecompressedSize < 0 || file.Limits.MaxCompressionRatio < 0 {
+			return vld, fmt.Errorf("%w: negative limit", ErrInvalidPolicy)
+		}
+		vld.SetDecompressionLimits(file.Limits.MaxDecompressedSize, file.Limits.MaxCompressionRatio)
+	}
+	if len(file.IDPrefix) > 0 && !isNCName(file.IDPrefix) {
+		return vld, fmt.Errorf("%w: invalid idPrefix %q", ErrInvalidPolicy, file.IDPrefix)
+	}
+	var (
+		enums [4]int
+		err   error
+	)
+	for i, enum := range []struct {
+		field, value string
+		names        []string
+	}{
+		{`idCheck`, file.IDCheck, severityNames},
+		{`rootCheck`, file.RootCheck, rootCheckNames},
+		{`doctype`, file.Doctype, doctypeNames},
+		{`parser`, file.Parser, parserNames},
+	} {
+		if enums[i], err = policyEnum(enum.field, enum.value, enum.names); err != nil {
+			return vld, err
+		}
+	}
+	vld.SetIDCheck(Severity(enums[0])).
+		SetRootCheck(RootCheck(enums[1])).
+		SetDoctypePolicy(DoctypePolicy(enums[2])).
+		SetParser(Parser(enums[3])).
+		SetOffline(file.Offline).
+		SetInlineSafe(file.InlineSafe).
+		PrefixIDs(file.IDPrefix).
+		SetAllowXMLStylesheet(file.AllowXMLStylesheet).
+		SetRequireUTF8(file.RequireUTF8).
+		SetStrictCase(file.StrictCase).
+		SetEditorNamespaces(file.EditorNamespaces).
+		SetFragmentHrefs(file.FragmentHrefs)
+	if file.AllowStyle {
+		vld.SetAllowStyle(true)
+	}
+	vld.SetCSSScope(file.CSSScope)
+	return vld, nil
+}
+
+func checkPolicyNames(field string, names []string) error {
+	for _, name := range names {
+		if !isXMLName(name) {
+			return fmt.Errorf("%w: invalid name %q in %s", ErrInvalidPolicy, name, field)
+		}
+	}
+	return nil
+}
+
+// policyEnum returns the value of an enum name, the empty name is the default
+func policyEnum(field, value string, names []string) (int, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+	for i, name := range names {
+		if name == value {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s must be one of %s, got %q", ErrInvalidPolicy, field, strings.Join(names, `, `), value)
+}
+
+// MarshalJSON returns the policy in the format read by LoadPolicy, with the
+// names sorted so equal policies give equal json. Policies with custom inner
+// text or attribute value validators return an ErrInvalidPolicy error, as
+// the validators would be lost.
+func (p *Policy) MarshalJSON() ([]byte, error) {
+	vld := &p.vld
+	if vld.customValidators {
+		return nil, fmt.Errorf("%w: custom validators cannot be written to a policy file", ErrInvalidPolicy)
+	}
+	file := policyFile{
+		Version:            PolicyVersion,
+		Elements:           sortedValues(vld.whiteListElements),
+		Attributes:         sortedValues(vld.whiteListAttributes),
+		Offline:            vld.offline,
+		InlineSafe:         vld.inlineSafe,
+		IDPrefix:           vld.idPrefix,
+		AllowXMLStylesheet: vld.allowXMLStylesheet,
+		RequireUTF8:        vld.requireUTF8,
+		StrictCase:         vld.strictCase,
+		EditorNamespaces:   vld.editorNamespaces,
+		AllowStyle:         vld.allowStyle,
+		FragmentHrefs:      vld.fragmentHrefs,
+	}
+	if vld.cssScope != nil {
+		file.CSSScope = &CSSScope{RenameClasses: vld.cssScope.RenameClasses}
+	}
+	if vld.idCheck != SeverityIgnore {
+		file.IDCheck = severityNames[vld.idCheck]
+	}
+	if vld.rootCheck != RootCheckSVGNamespace {
+		file.RootCheck = rootCheckNames[vld.rootCheck]
+	}
+	if vld.doctypePolicy != DoctypeReject {
+		file.Doctype = doctypeNames[vld.doctypePolicy]
+	}
+	if vld.parser != ParserEncodingXML {
+		file.Parser = parserNames[vld.parser]
+	}
+	for elem, allowed := range vld.elementAttributes {
+		if len(allowed) == 0 {
+			continue
+		}
+		if file.ElementAttributes == nil {
+			file.ElementAttributes = map[string][]string{}
+		}
+		file.ElementAttributes[elem] = sortedValues(allowed)
+	}
+	if vld.cssRules != nil {
+		file.CSS = &CSSRules{
+			ForbiddenProperties: sortedCopy(vld.cssRules.ForbiddenProperties),
+			ForbiddenAtRules:    sortedCopy(vld.cssRules.ForbiddenAtRules),
+		}
+	}
+	if vld.urlPolicy != nil {
+		file.URLs = &policyURLs{
+			Schemes:       sortedCopy(vld.urlPolicy.Schemes),
+			Hosts:         sortedCopy(vld.urlPolicy.Hosts),
+			AllowRelative: vld.urlPolicy.AllowRelative,
+		}
+	}
+	if vld.maxDecompressedSize > 0 || vld.maxCompressionRatio > 0 {
+		file.Limits = &policyLimits{
+			MaxDecompressedSize: vld.maxDecompressedSize,
+			MaxCompressionRatio: vld.maxCompressionRatio,
+		}
+	}
+	return json.Marshal(file)
+}
+
+// Fingerprint returns the sha256 hash of the json policy, e.g. to record
+// which policy checked a file in audit logs. Like MarshalJSON it fails for
+// policies with custom validators.
+func (p *Policy) Fingerprint() (string, error) {
+	b, err := p.MarshalJSON()
+	if err != nil {
+		return ``, err
+	}
+	sum := sha256.Sum256(b)
+	return `sha256:` + hex.EncodeToString(sum[:]), nil
+}
+
+func sortedValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func sortedCopy(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	list = append([]string(nil), list...)
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,132 @@
+package safesvg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func Test_LoadPolicy(t *testing.T) {
+	policy, err := LoadPolicy(strings.NewReader(`{
+		"version": 1,
+		"elements": ["svg", "g", "a", "rect", "style"],
+		"attributes": ["xmlns", "width", "height", "style", "viewBox"],
+		"elementAttributes": {"a": ["href"]},
+		"css": {"forbiddenProperties": ["position"], "forbiddenAtRules": ["@font-face"]},
+		"urls": {"schemes": ["https"], "hosts": ["example.com"]},
+		"limits": {"maxDecompressedSize": 1048576},
+		"idCheck": "error",
+		"strictCase": true
+	}`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	for _, svg := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 1 1"><a href="https://example.com/"><rect width="1" style="fill:red"/></a></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"><style>rect{fill:red}</style></svg>`,
+	} {
+		if err = policy.Validate([]byte(svg)); err != nil {
+			t.Errorf("%s: Unexptected error %v", svg, err)
+		}
+	}
+	for svg, expected := range map[string]error{
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect href="https://example.com/"/></svg>`:           ErrInvalidAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><a href="https://evil.example/"/></svg>`:             ErrUnallowedURL,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="position:fixed"/></svg>`:                ErrUnallowedCSSAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><style>@font-face{font-family:a}</style></svg>`:      ErrUnallowedCSSAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><circle/></svg>`:                                     ErrInvalidElement,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="posit\69on:fixed"/></svg>`:              ErrUnallowedCSSAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><style>@font\-face{font-family:a}</style></svg>`:     ErrUnallowedCSSAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg" viewbox="0 0 1 1"/>`:                                 ErrInvalidAttribute,
+		`<svg xmlns="http://www.w3.org/2000/svg"><rect style="a:&quot;x&#10;;position:fixed"/></svg>`: ErrUnallowedCSSAttributeValue,
+	} {
+		if err = policy.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, got %v", svg, expected, err)
+		}
+	}
+
+	b, err := policy.MarshalJSON()
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	reloaded, err := LoadPolicy(strings.NewReader(string(b)))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	fingerprint, err := policy.Fingerprint()
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if reloadedFingerprint, _ := reloaded.Fingerprint(); fingerprint != reloadedFingerprint || !strings.HasPrefix(fingerprint, `sha256:`) {
+		t.Errorf("Expected fingerprint %s, got %s", fingerprint, reloadedFingerprint)
+	}
+	v := reloaded.Validator()
+	v.WhitelistElements(`circle`)
+	if changed, _ := v.Compile().Fingerprint(); fingerprint == changed {
+		t.Errorf("Expected a different fingerprint for a different policy")
+	}
+	v.SetAttrValueValidator(`width`, func(string) error { return nil })
+	if _, err = v.Compile().MarshalJSON(); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Expected %v, got %v", ErrInvalidPolicy, err)
+	}
+	if _, err = v.Compile().Fingerprint(); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Expected %v, got %v", ErrInvalidPolicy, err)
+	}
+}
+
+func Test_LoadPolicyStrict(t *testing.T) {
+	for _, file := range []string{
+		`{"version": 1, "elements": ["svg"], "atributes": ["width"]}`,
+		`{"version": 2, "elements": ["svg"]}`,
+		`{"version": 1}`,
+		`{"version": 1, "elements": ["svg"], "idCheck": "fatal"}`,
+		`{"version": 1, "elements": ["<svg>"]}`,
+		`{"version": 1, "elements": ["svg"], "css": {"forbiddenAtRules": ["import"]}}`,
+		`{"version": 1, "elements": ["svg"], "urls": {"schemes": ["https:"]}}`,
+		`{"version": 1, "elements": ["svg"], "limits": {"maxCompressionRatio": -1}}`,
+		`{"version": 1, "elements": ["svg"]} {}`,
+	} {
+		if _, err := LoadPolicy(strings.NewReader(file)); !errors.Is(err, ErrInvalidPolicy) {
+			t.Errorf("%s: Expected %v, got %v", file, ErrInvalidPolicy, err)
+		}
+	}
+}
+
+func Test_PolicyRoundTrip(t *testing.T) {
+	const head = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink">`
+	docs := []string{
+		`<use href="https://evil.example/x.svg#a"/>`,
+		`<use xlink:href="a.svg#a"/>`,
+		`<g id="a"/><use href="#a"/>`,
+		`<rect style="fill:red"/>`,
+		`<filter id="f"><feColorMatrix values="0"/></filter>`,
+	}
+	for _, profile := range []Profile{ProfileDefault, ProfileIcon, ProfileIllustration, ProfileDOMPurify, ProfileSVG11, ProfileSVG2, ProfileDesignTool} {
+		v, err := NewValidatorProfile(profile)
+		if err != nil {
+			t.Fatalf("Unexptected error %v", err)
+		}
+		b, err := v.Compile().MarshalJSON()
+		if profile == ProfileDOMPurify {
+			// IS_ALLOWED_URI is checked by attribute value validators
+			if !errors.Is(err, ErrInvalidPolicy) {
+				t.Errorf("%s: Expected %v, got %v", profile, ErrInvalidPolicy, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: Unexptected error %v", profile, err)
+		}
+		reloaded, err := LoadPolicy(strings.NewReader(string(b)))
+		if err != nil {
+			t.Fatalf("%s: Unexptected error %v", profile, err)
+		}
+		for _, doc := range docs {
+			svg := []byte(head + doc + `</svg>`)
+			if expected, err := v.Validate(svg), reloaded.Validate(svg); fmt.Sprint(err) != fmt.Sprint(expected) {
+				t.Errorf("%s %s: Expected %v, got %v", profile, doc, expected, err)
+			}
+		}
+	}
+}
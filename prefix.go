@@ -0,0 +1,133 @@
+package safesvg
+
+import (
+	"strings"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// PrefixIDs makes Sanitize prepend prefix to every id and to every reference
+// to an id: href and xlink:href fragments, url(#...) in attribute values and
+// stylesheets, id selectors in stylesheets and the syncbase and event values
+// of begin and end ("a.end", "a.click+1s"). It is used to embed many svg
+// documents into one page without their ids colliding. The prefix must be an
+// xml name without colon, like the ids, or Sanitize returns an ErrInvalidID
+// error.
+func (vld *Validator) PrefixIDs(prefix string) *Validator {
+	vld.idPrefix = prefix
+	return vld
+}
+
+func (vld *Validator) prefixID(id string) string {
+	return vld.idPrefix + id
+}
+
+// prefixIDs rewrites the ids defined or referenced by an attribute
+func (vld *Validator) prefixIDs(key string, value string) string {
+	switch {
+	case isIDAttribute(key):
+		return vld.prefixID(value)
+	case key == `begin` || key == `end`:
+		return rewriteTimingRefs(value, vld.prefixID)
+	}
+	return rewriteIDRefs(key, value, vld.prefixID)
+}
+
+// rewriteTimingRefs passes the ids of the syncbase and event values of a
+// begin or end attribute through fn
+func rewriteTimingRefs(value string, fn func(id string) string) string {
+	parts := strings.Split(value, `;`)
+	for i, part := range parts {
+		trimmed := strings.TrimLeft(part, " \t\r\n")
+		dot := indexUnescapedDot(trimmed)
+		if dot <= 0 {
+			continue
+		}
+		id := trimmed[:dot]
+		if !isNCName(strings.ReplaceAll(id, `\`, ``)) {
+			continue
+		}
+		parts[i] = part[:len(part)-len(trimmed)] + fn(id) + trimmed[dot:]
+	}
+	return strings.Join(parts, `;`)
+}
+
+// indexUnescapedDot returns the index of the first '.' not escaped by a backslash
+func indexUnescapedDot(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '.':
+			return i
+		}
+	}
+	return -1
+}
+
+// cssGroupingRules are the at-rules whose block contains rules rather than declarations
+var cssGroupingRules = map[string]struct{}{
+	`@media`:     {},
+	`@supports`:  {},
+	`@document`:  {},
+	`@layer`:     {},
+	`@container`: {},
+	`@scope`:     {},
+}
+
+// rewriteCSS rebuilds a stylesheet from its tokens, passing each one through
+// fn. selector is true for the tokens of a selector. If css cannot be
+// tokenized to the end, e.g. on an unclosed string, the TokenError is passed
+// to fn as well and the rebuilt stylesheet ends before it.
+func rewriteCSS(css string, fn func(token *scanner.Token, selector bool) string) string {
+	var (
+		b      strings.Builder
+		blocks []bool // true for a declaration block
+		atRule string
+	)
+	s := scanner.New(css)
+	for {
+		token := s.Next()
+		if token.Type == scanner.TokenEOF {
+			break
+		}
+		if token.Type == scanner.TokenError {
+			fn(token, false)
+			break
+		}
+		selector := len(atRule) == 0 && (len(blocks) == 0 || !blocks[len(blocks)-1])
+		switch token.Type {
+		case scanner.TokenAtKeyword:
+			atRule = strings.ToLower(token.Value)
+		case scanner.TokenChar:
+			switch token.Value {
+			case `{`:
+				_, grouping := cssGroupingRules[atRule]
+				blocks = append(blocks, !grouping)
+				atRule = ``
+			case `}`:
+				if len(blocks) > 0 {
+					blocks = blocks[:len(blocks)-1]
+				}
+			case `;`:
+				atRule = ``
+			}
+		}
+		b.WriteString(fn(token, selector))
+	}
+	return b.String()
+}
+
+// rewriteCSSIDs passes the ids referenced by url(#...) and id selectors of a
+// stylesheet through fn
+func rewriteCSSIDs(css string, fn func(id string) string) string {
+	return rewriteCSS(css, func(token *scanner.Token, selector bool) string {
+		switch {
+		case token.Type == scanner.TokenURI:
+			return rewriteIDRefs(`style`, token.Value, fn)
+		case token.Type == scanner.TokenHash && selector:
+			return `#` + fn(token.Value[1:])
+		}
+		return token.Value
+	})
+}
@@ -0,0 +1,42 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_PrefixIDs(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><style>#a{fill:#fff}@media screen{#clip0 rect{fill:url(#a)}}</style><defs><clipPath id="clip0"><rect/></clipPath><linearGradient id="a"/></defs><rect id="r" clip-path="url(#clip0)" style="fill:url('#a')"/><use xlink:href="#r"/><use href="#r"/><animate begin="r.end+1s; 2.5s; click; indefinite" end="x\.y.click"/></svg>`)
+	v := NewValidator()
+	v.WhitelistElements(`style`, `animate`)
+	v.PrefixIDs(`icon1-`)
+	out, err := v.Sanitize(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><style>#icon1-a{fill:#fff}@media screen{#icon1-clip0 rect{fill:url(#icon1-a)}}</style><defs><clipPath id="icon1-clip0"><rect/></clipPath><linearGradient id="icon1-a"/></defs><rect id="icon1-r" clip-path="url(#icon1-clip0)" style="fill:url('#icon1-a')"/><use xlink:href="#icon1-r"/><use href="#icon1-r"/><animate begin="icon1-r.end+1s; 2.5s; click; indefinite" end="icon1-x\.y.click"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	if err = v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	v = NewValidator()
+	v.WhitelistElements(`style`).PrefixIDs(`p-`).SetIDCheck(SeverityError)
+	out, err = v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><style>rect{fill:url(#g)}</style><linearGradient id="g"/><rect/></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected = `<svg xmlns="http://www.w3.org/2000/svg"><style>rect{fill:url(#p-g)}</style><linearGradient id="p-g"/><rect/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	for _, prefix := range []string{`x{}body{display:none}#`, `1-`, `a:`} {
+		v.PrefixIDs(prefix)
+		if _, err = v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect id="a"/></svg>`)); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("%s: Expected %v, got %v", prefix, ErrInvalidID, err)
+		}
+	}
+}
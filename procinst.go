@@ -0,0 +1,177 @@
+package safesvg
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	pseudoAttrRegexp   = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9_-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	xmlEncodingRegexp  = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+	xmlDeclPseudoAttrs = []string{`version`, `encoding`, `standalone`}
+)
+
+// SetAllowXMLStylesheet allows <?xml-stylesheet?> processing instructions
+// of type text/css. Their href must be a fragment ("#style") unless a url
+// policy allowing it is set.
+func (vld *Validator) SetAllowXMLStylesheet(allow bool) *Validator {
+	vld.allowXMLStylesheet = allow
+	return vld
+}
+
+// parsePseudoAttrs parses the name="value" pairs of a processing instruction
+func parsePseudoAttrs(inst []byte) ([]xml.Attr, error) {
+	var attrs []xml.Attr
+	s := string(inst)
+	for len(strings.TrimSpace(s)) > 0 {
+		m := pseudoAttrRegexp.FindStringSubmatchIndex(s)
+		if m == nil {
+			return nil, fmt.Errorf("%w: malformed pseudo-attributes: %s", ErrInvalidProcInst, inst)
+		}
+		value := s[m[4]:m[5]]
+		if m[4] < 0 {
+			value = s[m[6]:m[7]]
+		}
+		name := s[m[2]:m[3]]
+		for _, attr := range attrs {
+			if attr.Name.Local == name {
+				return nil, fmt.Errorf("%w: duplicate pseudo-attribute %s", ErrInvalidProcInst, name)
+			}
+		}
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+		s = s[m[1]:]
+	}
+	return attrs, nil
+}
+
+// checkProcInst checks a <?target inst?> processing instruction and reports
+// whether it must be dropped. In sanitize mode the href of xml-stylesheet is
+// passed through the url policy, whose RewriteURL errors abort Sanitize.
+func (w *walker) checkProcInst(v *xml.ProcInst) (drop bool, err error) {
+	// targets matching xml in any case are reserved
+	switch strings.ToLower(v.Target) {
+	case `xml`:
+		err = w.checkXMLDecl(v)
+	case `xml-stylesheet`:
+		err = w.checkXMLStylesheet(v)
+	default:
+		err = fmt.Errorf("%w: %s", ErrInvalidProcInst, v.Target)
+	}
+	var rewriteErr *RewriteURLError
+	if err != nil && w.sanitize() && !errors.As(err, &rewriteErr) {
+		return true, nil
+	}
+	return false, err
+}
+
+func (w *walker) checkXMLDecl(v *xml.ProcInst) error {
+	if w.started {
+		return fmt.Errorf("%w: xml declaration is not at the start of the document", ErrInvalidProcInst)
+	}
+	attrs, err := parsePseudoAttrs(v.Inst)
+	if err != nil {
+		return err
+	}
+	next := 0
+	for _, attr := range attrs {
+		i := next
+		for i < len(xmlDeclPseudoAttrs) && xmlDeclPseudoAttrs[i] != attr.Name.Local {
+			i++
+		}
+		if i == len(xmlDeclPseudoAttrs) {
+			return fmt.Errorf("%w: unexpected xml declaration pseudo-attribute %s", ErrInvalidProcInst, attr.Name.Local)
+		}
+		next = i + 1
+		var valid bool
+		switch attr.Name.Local {
+		case `version`:
+			valid = attr.Value == `1.0`
+		case `encoding`:
+			valid = xmlEncodingRegexp.MatchString(attr.Value)
+		case `standalone`:
+			valid = attr.Value == `yes` || attr.Value == `no`
+		}
+		if !valid {
+			return fmt.Errorf("%w: %s=%q", ErrInvalidProcInst, attr.Name.Local, attr.Value)
+		}
+	}
+	if len(attrs) == 0 || attrs[0].Name.Local != `version` {
+		return fmt.Errorf("%w: xml declaration without version", ErrInvalidProcInst)
+	}
+	if w.sanitize() {
+		// the output is always UTF-8
+		for i, attr := range attrs {
+			if attr.Name.Local == `encoding` {
+				attrs[i].Value = `UTF-8`
+			}
+		}
+		v.Inst = formatPseudoAttrs(attrs)
+	}
+	return nil
+}
+
+func formatPseudoAttrs(attrs []xml.Attr) []byte {
+	var inst strings.Builder
+	for i, attr := range attrs {
+		if i > 0 {
+			inst.WriteString(` `)
+		}
+		inst.WriteString(attr.Name.Local + `="` + attrEscaper.Replace(attr.Value) + `"`)
+	}
+	return []byte(inst.String())
+}
+
+func (w *walker) checkXMLStylesheet(v *xml.ProcInst) error {
+	if !w.vld.allowXMLStylesheet && !w.vld.offline {
+		return fmt.Errorf("%w: %s", ErrInvalidProcInst, v.Target)
+	}
+	attrs, err := parsePseudoAttrs(v.Inst)
+	if err != nil {
+		return err
+	}
+	var href string
+	for _, attr := range attrs {
+		switch attr.Name.Local {
+		case `href`:
+			href = attr.Value
+		case `type`:
+			if !strings.EqualFold(attr.Value, `text/css`) {
+				return fmt.Errorf("%w: %s type=%q", ErrInvalidProcInst, v.Target, attr.Value)
+			}
+		}
+	}
+	if w.vld.offline && !isLocalURL(href) {
+		ref := `<?` + v.Target + ` href="` + href + `"?>`
+		if w.sanitize() {
+			return fmt.Errorf("%w: %s", ErrNetworkReference, ref)
+		}
+		w.network = append(w.network, ref)
+		return nil
+	}
+	if !w.vld.allowXMLStylesheet {
+		return fmt.Errorf("%w: %s", ErrInvalidProcInst, v.Target)
+	}
+	if len(href) == 0 {
+		return fmt.Errorf("%w: %s without href", ErrInvalidProcInst, v.Target)
+	}
+	if strings.HasPrefix(strings.TrimSpace(href), `#`) {
+		return nil
+	}
+	if w.vld.urlPolicy == nil || isLocalURL(href) { // data: stylesheets would bypass ValidateStyle
+		return fmt.Errorf("%w: %s", ErrUnallowedURL, href)
+	}
+	rewritten, err := w.vld.urlPolicy.apply(v.Target+`:href`, href, w.sanitize())
+	if err != nil || rewritten == href {
+		return err
+	}
+	for i, attr := range attrs {
+		if attr.Name.Local == `href` {
+			attrs[i].Value = rewritten
+		}
+	}
+	v.Inst = formatPseudoAttrs(attrs)
+	return nil
+}
@@ -0,0 +1,155 @@
+package safesvg
+
+import (
+	"fmt"
+)
+
+// Profile is a named whitelist of elements and attributes
+type Profile int
+
+const (
+	// ProfileDefault is the whitelist of NewValidator: DOMPurify's svg and
+	// svgFilters profiles without <a>, <style> and <feImage>, plus <use> and
+	// baseProfile.
+	ProfileDefault Profile = iota
+	// ProfileIcon allows plain shapes for icons, without text, filters,
+	// images, animation, links or css (no <style>, no style attribute).
+	// href and xlink:href only reference ids of the document, see
+	// Validator.SetFragmentHrefs.
+	// Elements: svg g defs symbol use title desc path rect circle ellipse
+	// line polyline polygon clipPath mask linearGradient radialGradient stop.
+	// Attributes: xmlns xmlns:xlink version id class href xlink:href x y
+	// width height viewBox preserveAspectRatio transform d pathLength points
+	// cx cy r rx ry x1 y1 x2 y2 fill fill-opacity fill-rule stroke
+	// stroke-width stroke-opacity stroke-linecap stroke-linejoin
+	// stroke-miterlimit stroke-dasharray stroke-dashoffset opacity color
+	// display visibility shape-rendering clip-path clip-rule clipPathUnits
+	// mask maskUnits maskContentUnits gradientUnits gradientTransform
+	// spreadMethod fx fy offset stop-color stop-opacity.
+	ProfileIcon
+	// ProfileIllustration is ProfileDefault without animation.
+	// Elements: the svg_elements and svg_filters_elements of default.go and
+	// use, without a, style, feImage, animateColor, animateMotion,
+	// animateTransform and mpath. Text, filters, gradients, patterns,
+	// markers, masks and <image> are allowed.
+	// Attributes: the svg_attributes and xml_attributes of default.go and
+	// baseProfile, without accumulate additive attributeName attributeType
+	// begin by dur end keyPoints keySplines keyTimes max min repeatCount
+	// repeatDur restart. values is kept for feColorMatrix.
+	// CSS: the style attribute, checked by ValidateStyle like in
+	// ProfileDefault; <style> is rejected, so there are no selectors,
+	// at-rules or @keyframes animations.
+	ProfileIllustration
+	// ProfileDOMPurify is DOMPurify's default for svg documents, the
+	// validator of NewValidatorFromDOMPurify with an empty configuration.
+	// Elements: the svg_elements and svg_filters_elements of default.go,
+	// including <style> and <feImage>, but not <use>.
+	// Attributes: the svg_attributes and xml_attributes of default.go,
+	// without baseProfile.
+	// CSS: <style> text and style attributes are checked by ValidateStyle
+	// only, without the selector checks of Validator.SetAllowStyle, so a
+	// stylesheet can restyle the page the svg is inlined into. <feImage> can
+	// fetch external images, use Validator.SetURLPolicy or SetOffline.
+	// URLs: attribute values are matched against DOMPurify's IS_ALLOWED_URI
+	// by attribute value validators, so the profile cannot be written to a
+	// policy file.
+	ProfileDOMPurify
+	// ProfileSVG11 is ProfileDefault without the SVG 2 additions: no
+	// feDropShadow element and no href (use xlink:href), paint-order,
+	// vector-effect, mix-blend-mode, isolation, transform-origin and
+	// transform-box attributes.
+	ProfileSVG11
+	// ProfileSVG2 is ProfileDefault with the SVG 2 attributes it lacks:
+	// vector-effect, mix-blend-mode, isolation and transform-box, as used by
+	// Figma and Illustrator exports. feDropShadow, href, paint-order and
+	// transform-origin are already in ProfileDefault.
+	ProfileSVG2
+	// ProfileDesignTool is ProfileSVG2 tolerating the editor namespaces of
+	// Inkscape, Illustrator, Sketch and Figma exports, see
+	// Validator.SetEditorNamespaces.
+	ProfileDesignTool
+)
+
+var iconElements = []string{
+	`svg`, `g`, `defs`, `symbol`, `use`, `title`, `desc`,
+	`path`, `rect`, `circle`, `ellipse`, `line`, `polyline`, `polygon`,
+	`clipPath`, `mask`, `linearGradient`, `radialGradient`, `stop`,
+}
+
+var iconAttributes = []string{
+	`xmlns`, `xmlns:xlink`, `version`, `id`, `class`, `href`, `xlink:href`,
+	`x`, `y`, `width`, `height`, `viewBox`, `preserveAspectRatio`, `transform`,
+	`d`, `pathLength`, `points`, `cx`, `cy`, `r`, `rx`, `ry`, `x1`, `y1`, `x2`, `y2`,
+	`fill`, `fill-opacity`, `fill-rule`, `stroke`, `stroke-width`, `stroke-opacity`,
+	`stroke-linecap`, `stroke-linejoin`, `stroke-miterlimit`, `stroke-dasharray`,
+	`stroke-dashoffset`, `opacity`, `color`, `display`, `visibility`,
+	`shape-rendering`, `clip-path`, `clip-rule`, `clipPathUnits`, `mask`,
+	`maskUnits`, `maskContentUnits`, `gradientUnits`, `gradientTransform`,
+	`spreadMethod`, `fx`, `fy`, `offset`, `stop-color`, `stop-opacity`,
+}
+
+var animationElements = []string{`animateColor`, `animateMotion`, `animateTransform`, `mpath`}
+
+// animationAttributes are only used by animation elements, unlike values
+// which feColorMatrix uses too
+var animationAttributes = []string{
+	`accumulate`, `additive`, `attributeName`, `attributeType`, `begin`, `by`,
+	`dur`, `end`, `keyPoints`, `keySplines`, `keyTimes`, `max`, `min`,
+	`repeatCount`, `repeatDur`, `restart`,
+}
+
+var svg2Elements = []string{`feDropShadow`}
+
+var svg2Attributes = []string{
+	`href`, `paint-order`, `vector-effect`, `mix-blend-mode`, `isolation`,
+	`transform-origin`, `transform-box`,
+}
+
+// NewValidatorProfile creates a new validator with the whitelists of a profile
+func NewValidatorProfile(profile Profile) (Validator, error) {
+	vld := NewValidator()
+	switch profile {
+	case ProfileDefault:
+	case ProfileIcon:
+		vld.whiteListElements = map[string]string{}
+		vld.whiteListAttributes = map[string]string{}
+		vld.WhitelistElements(iconElements...)
+		vld.WhitelistAttributes(iconAttributes...)
+		vld.SetFragmentHrefs(true)
+	case ProfileIllustration:
+		vld.BlacklistElements(animationElements...)
+		vld.BlacklistAttributes(animationAttributes...)
+	case ProfileSVG11:
+		vld.BlacklistElements(svg2Elements...)
+		vld.BlacklistAttributes(svg2Attributes...)
+	case ProfileSVG2, ProfileDesignTool:
+		vld.WhitelistElements(svg2Elements...)
+		vld.WhitelistAttributes(svg2Attributes...)
+		vld.SetEditorNamespaces(profile == ProfileDesignTool)
+	case ProfileDOMPurify:
+		return NewValidatorFromDOMPurify([]byte(`{}`))
+	default:
+		return vld, fmt.Errorf("%w: unknown profile %d", ErrInvalidPolicy, profile)
+	}
+	return vld, nil
+}
+
+func (p Profile) String() string {
+	switch p {
+	case ProfileDefault:
+		return `default`
+	case ProfileIcon:
+		return `icon`
+	case ProfileIllustration:
+		return `illustration`
+	case ProfileDOMPurify:
+		return `dompurify`
+	case ProfileSVG11:
+		return `svg1.1`
+	case ProfileSVG2:
+		return `svg2`
+	case ProfileDesignTool:
+		return `design-tool`
+	}
+	return fmt.Sprintf(`Profile(%d)`, int(p))
+}
@@ -0,0 +1,52 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Profiles(t *testing.T) {
+	const head = `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24">`
+	for _, test := range []struct {
+		profile Profile
+		body    string
+		err     error
+	}{
+		{ProfileIcon, `<g><path d="M0 0h24" fill="red"/><use href="#a"/></g>`, nil},
+		{ProfileIcon, `<text>a</text>`, ErrInvalidElement},
+		{ProfileIcon, `<filter/>`, ErrInvalidElement},
+		{ProfileIcon, `<image/>`, ErrInvalidElement},
+		{ProfileIcon, `<animateTransform/>`, ErrInvalidElement},
+		{ProfileIcon, `<rect style="fill:red"/>`, ErrInvalidAttribute},
+		{ProfileIcon, `<use href="https://evil.example/a.svg#a"/>`, ErrUnallowedHrefAttributeValue},
+		{ProfileIcon, `<use href="a.svg#a"/>`, ErrUnallowedHrefAttributeValue},
+		{ProfileIcon, `<use xlink:href="data:image/svg+xml,a" xmlns:xlink="http://www.w3.org/1999/xlink"/>`, ErrUnallowedHrefAttributeValue},
+		{ProfileIllustration, `<filter id="f"><feGaussianBlur stdDeviation="2"/></filter><text style="fill:red">a</text>`, nil},
+		{ProfileIllustration, `<filter id="f"><feColorMatrix type="matrix" values="0"/></filter>`, nil},
+		{ProfileIllustration, `<animateMotion/>`, ErrInvalidElement},
+		{ProfileIllustration, `<rect begin="1s"/>`, ErrInvalidAttribute},
+		{ProfileDOMPurify, `<style>rect{fill:red}</style><a href="#a"><rect/></a>`, nil},
+		{ProfileDOMPurify, `<use href="#a"/>`, ErrInvalidElement},
+		{ProfileDOMPurify, `<style>body{display:none}</style><filter id="f"><feImage href="#a"/></filter>`, nil},
+		{ProfileDOMPurify, `<g baseProfile="full"/>`, ErrInvalidAttribute},
+		{ProfileSVG2, `<filter id="f"><feDropShadow dx="1"/></filter><use href="#f"/><path vector-effect="non-scaling-stroke" paint-order="stroke" style="mix-blend-mode:multiply" mix-blend-mode="multiply" isolation="isolate" transform-origin="center" transform-box="fill-box"/>`, nil},
+		{ProfileSVG11, `<use xlink:href="#a" xmlns:xlink="http://www.w3.org/1999/xlink"/>`, nil},
+		{ProfileSVG11, `<use href="#a"/>`, ErrInvalidAttribute},
+		{ProfileSVG11, `<filter><feDropShadow/></filter>`, ErrInvalidElement},
+		{ProfileSVG11, `<path vector-effect="non-scaling-stroke"/>`, ErrInvalidAttribute},
+		{ProfileDefault, `<path vector-effect="non-scaling-stroke"/>`, ErrInvalidAttribute},
+		{ProfileDefault, `<style>rect{fill:red}</style>`, ErrInvalidElement},
+	} {
+		v, err := NewValidatorProfile(test.profile)
+		if err != nil {
+			t.Fatalf("Unexptected error %v", err)
+		}
+		svg := head + test.body + `</svg>`
+		if err = v.Validate([]byte(svg)); !errors.Is(err, test.err) {
+			t.Errorf("%s %s: Expected %v, got %v", test.profile, svg, test.err, err)
+		}
+	}
+	if _, err := NewValidatorProfile(Profile(42)); !errors.Is(err, ErrInvalidPolicy) {
+		t.Errorf("Expected %v, got %v", ErrInvalidPolicy, err)
+	}
+}
@@ -0,0 +1,59 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// RootCheck decides how the document structure is checked
+type RootCheck int
+
+const (
+	// RootCheckSVGNamespace requires exactly one root svg element in the svg
+	// namespace and nothing but whitespace, comments and processing
+	// instructions outside of it
+	RootCheckSVGNamespace RootCheck = iota
+	// RootCheckSVG is RootCheckSVGNamespace without the namespace check, for
+	// svg fragments meant to be inlined into html
+	RootCheckSVG
+	// RootCheckNone accepts any sequence of whitelisted elements
+	RootCheckNone
+)
+
+// SetRootCheck sets how the root element is checked, RootCheckSVGNamespace by default
+func (vld *Validator) SetRootCheck(check RootCheck) *Validator {
+	vld.rootCheck = check
+	return vld
+}
+
+// checkRoot checks an element started outside of any other element and
+// reports whether it must be dropped
+func (w *walker) checkRoot(v xml.StartElement, elem string) (drop bool, err error) {
+	if w.rootSeen {
+		if w.sanitize() {
+			return true, nil
+		}
+		return false, fmt.Errorf("%w: element <%s> after the root element", ErrInvalidDocument, v.Name.Local)
+	}
+	w.rootSeen = true
+	if elem != `svg` {
+		return false, fmt.Errorf("%w: root element <%s> is not svg", ErrInvalidDocument, v.Name.Local)
+	}
+	if w.vld.rootCheck == RootCheckSVGNamespace && v.Name.Space != nsSVG {
+		return false, fmt.Errorf("%w: root element is not in the %s namespace", ErrInvalidDocument, nsSVG)
+	}
+	return false, nil
+}
+
+// checkTopLevelText checks text outside of the root element and reports
+// whether it must be dropped
+func (w *walker) checkTopLevelText(v xml.CharData) (drop bool, err error) {
+	if len(bytes.TrimSpace(v)) == 0 {
+		return false, nil
+	}
+	if w.sanitize() {
+		return true, nil
+	}
+	return false, fmt.Errorf("%w: text outside of the root element: %.20q", ErrInvalidDocument, v)
+}
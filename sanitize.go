@@ -0,0 +1,27 @@
+package safesvg
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Sanitize returns the svg data with every element, attribute and text that
+// fails validation removed. Errors that cannot be fixed by removing content,
+// such as malformed xml or too many references, are still returned.
+func (vld Validator) Sanitize(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := vld.SanitizeReader(&buf, bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SanitizeReader sanitizes svg data from an io.Reader and writes the result to w
+func (vld Validator) SanitizeReader(w io.Writer, r io.Reader) error {
+	if len(vld.idPrefix) > 0 && !isNCName(vld.idPrefix) {
+		// it is also written into the selectors of stylesheets
+		return fmt.Errorf("%w: invalid id prefix %q", ErrInvalidID, vld.idPrefix)
+	}
+	return vld.walk(r, newXMLWriter(w))
+}
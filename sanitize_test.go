@@ -0,0 +1,99 @@
+package safesvg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func Test_Sanitize(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="24" onload="alert(1)"><script>alert(1)</script><g><path d="M0 0h24"/><image xlink:href="javascript:alert(1)" width="1"/></g></svg>`)
+	v := NewValidator()
+	out, err := v.Sanitize(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="24"><g><path d="M0 0h24"/><image width="1"/></g></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
+
+func Test_URLPolicy(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="https://img.cdn.example/a.png"/><image href="http://img.cdn.example/a.png"/><rect fill="url(https://evil.example/a.svg#p)"/><use xlink:href="#a"/></svg>`)
+	v := NewValidator()
+	v.SetURLPolicy(&URLPolicy{
+		Schemes: []string{`https`},
+		Hosts:   []string{`*.cdn.example`},
+		RewriteURL: func(attr, value string) (string, error) {
+			return `https://proxy.cdn.example/?url=` + value, nil
+		},
+	})
+	err := v.Validate(svg)
+	if !errors.Is(err, ErrUnallowedURL) {
+		t.Errorf("Expected %v, got %v", ErrUnallowedURL, err)
+	}
+	out, err := v.Sanitize(svg)
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><image xlink:href="https://proxy.cdn.example/?url=https://img.cdn.example/a.png"/><image/><rect/><use xlink:href="#a"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	for name, rewrite := range map[string]func(attr, value string) (string, error){
+		`error`: func(attr, value string) (string, error) {
+			return ``, errors.New(`proxy down`)
+		},
+		`unallowed result`: func(attr, value string) (string, error) {
+			return `https://evil.example/?url=` + value, nil
+		},
+	} {
+		v.SetURLPolicy(&URLPolicy{Schemes: []string{`https`}, Hosts: []string{`*.cdn.example`}, RewriteURL: rewrite})
+		var rewriteErr *RewriteURLError
+		if _, err = v.Sanitize(svg); !errors.As(err, &rewriteErr) || rewriteErr.URL != `https://img.cdn.example/a.png` {
+			t.Errorf("%s: Expected a *RewriteURLError, got %v", name, err)
+		}
+	}
+
+	policy := &URLPolicy{Schemes: []string{`https`}, Hosts: []string{`*.cdn.example`}}
+	for value, allowed := range map[string]bool{
+		`#a`:                       true,
+		`data:image/png;base64,AA`: true,
+		`https://a.cdn.example/x`:  true,
+		`https://cdn.example/x`:    false,
+		`https://evilcdn.example/`: false,
+		`//a.cdn.example/x`:        false,
+		`a.png`:                    false,
+		`/\\evil.example/x`:        false,
+		"/\t/evil.example/x":       false,
+	} {
+		if err := policy.Allow(value); (err == nil) != allowed {
+			t.Errorf("%s: expected allowed=%v, got %v", value, allowed, err)
+		}
+	}
+	policy.AllowRelative = true
+	if err := policy.Allow(`img/a.png`); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+
+	v.SetURLPolicy(&URLPolicy{Schemes: []string{`https`}, Hosts: []string{`*.cdn.example`}})
+	for _, style := range []string{
+		`fill:u\72l(https://evil.example/a.svg#p)`,
+		`fill:image-set("https://evil.example/a.png" 1x)`,
+		"a:\"x\n;fill:url(https://evil.example/t.png)",
+	} {
+		svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect style='` + strings.ReplaceAll(style, "\n", `&#10;`) + `'/></svg>`)
+		if err := v.Validate(svg); !errors.Is(err, ErrUnallowedURL) {
+			t.Errorf("%s: Expected %v, got %v", style, ErrUnallowedURL, err)
+		}
+	}
+	out, err = v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect style='a:"x&#10;;fill:url(https://evil.example/t.png)'/><text font-family="it's"/></svg>`))
+	if expected := `<svg xmlns="http://www.w3.org/2000/svg"><rect/><text font-family="it's"/></svg>`; err != nil || string(out) != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+	}
+}
@@ -0,0 +1,238 @@
+package safesvg
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// CSSScope scopes the <style> elements of sanitized documents to their root
+// svg element, so that documents inlined into one html page do not restyle
+// each other or the page
+type CSSScope struct {
+	// ID returns the id given to the root svg elements, a random "svg-" id
+	// by default. It must be unique and an xml name without colon, other
+	// values are replaced by a random id.
+	ID func() string `json:"-"`
+	// RenameClasses prefixes the names in class attributes and the class
+	// selectors of stylesheets with the root id and "-", so that the rules of
+	// the page do not match the elements of the svg either
+	RenameClasses bool `json:"renameClasses,omitempty"`
+}
+
+// SetCSSScope makes Sanitize prefix every selector of <style> elements with
+// a new id of the root svg element, e.g. ".cls-1" becomes "#svg-1a2b3c .cls-1",
+// nil removes the scoping. Selectors then only match the descendants of the
+// root element. An id the root element already has is replaced, along with
+// the references to it, as exports of the same tool share their root ids,
+// e.g. "Layer_1" in Illustrator.
+func (vld *Validator) SetCSSScope(scope *CSSScope) *Validator {
+	vld.cssScope = scope
+	return vld
+}
+
+var (
+	randRead = rand.Read
+	// scopeCounter numbers the scope ids when no random bytes can be read
+	scopeCounter uint64
+)
+
+func randomScopeID() string {
+	b := make([]byte, 6)
+	if _, err := randRead(b); err != nil {
+		return `svg-` + strconv.FormatUint(atomic.AddUint64(&scopeCounter, 1), 10)
+	}
+	return `svg-` + hex.EncodeToString(b)
+}
+
+// scope gives the root element its scope id, replacing its id, and renames
+// the classes of v
+func (w *walker) scope(v *xml.StartElement) {
+	if w.depth == 1 {
+		w.scopeID, w.scopeReplaced = ``, ``
+		w.scopeElem = v.Name.Local
+		if w.vld.cssScope.ID != nil {
+			w.scopeID = w.vld.cssScope.ID()
+		}
+		if !isNCName(w.scopeID) {
+			w.scopeID = randomScopeID()
+		}
+		found := false
+		for i, attr := range v.Attr {
+			if len(attr.Name.Space) == 0 && attr.Name.Local == `id` {
+				w.scopeReplaced = attr.Value
+				v.Attr[i].Value = w.scopeID
+				found = true
+			}
+		}
+		if !found {
+			v.Attr = append(v.Attr, xml.Attr{Name: xml.Name{Local: `id`}, Value: w.scopeID})
+		}
+		if w.vld.idCheck != SeverityIgnore {
+			if w.ids == nil {
+				w.ids = map[string]struct{}{}
+			}
+			w.ids[w.scopeID] = struct{}{}
+		}
+	}
+	if !w.vld.cssScope.RenameClasses || len(w.scopeID) == 0 {
+		return
+	}
+	for i, attr := range v.Attr {
+		if len(attr.Name.Space) == 0 && attr.Name.Local == `class` {
+			classes := strings.Fields(attr.Value)
+			for j, class := range classes {
+				classes[j] = w.scopeID + `-` + class
+			}
+			v.Attr[i].Value = strings.Join(classes, ` `)
+		}
+	}
+}
+
+// scopeCSS prefixes the selectors of a stylesheet with the id selector of
+// the root element and, if renameClasses, the class selectors with the id,
+// including those in selector functions like :is(). A selector whose first
+// compound selector holds the id already points into the root element and
+// is kept, one whose first compound selector is of the type of the root
+// element is scoped to the root itself as well as to its descendants.
+func scopeCSS(css string, id string, root string, renameClasses bool) string {
+	var (
+		depth    int             // in functions
+		brackets int             // in attribute selectors
+		class    bool            // after a '.' of a selector
+		sel      strings.Builder // the selector up to the current token
+		typeEnd  int             // end of the root type selector starting sel
+		compound bool            // past the first compound selector
+		anchored bool            // the first compound selector holds the id
+	)
+	scope := `#` + cssEscape(id)
+	// flush returns the selector in sel scoped to the root element
+	flush := func() string {
+		selector := sel.String()
+		end := typeEnd
+		scoped := anchored
+		sel.Reset()
+		typeEnd, compound, anchored = 0, false, false
+		switch {
+		case len(selector) == 0 || scoped:
+			return selector
+		case end > 0:
+			return selector[:end] + scope + selector[end:] + `,` + scope + ` ` + selector
+		}
+		return scope + ` ` + selector
+	}
+	scoped := rewriteCSS(css, func(token *scanner.Token, selector bool) string {
+		if !selector {
+			return flush() + token.Value
+		}
+		value := token.Value
+		top := depth == 0 && brackets == 0
+		switch token.Type {
+		case scanner.TokenS, scanner.TokenComment:
+			if sel.Len() == 0 {
+				return value
+			}
+			compound = compound || top
+			sel.WriteString(value)
+			return ``
+		case scanner.TokenAtKeyword:
+			return flush() + value
+		case scanner.TokenHash:
+			if top && !compound && cssUnescape(value[1:]) == id {
+				anchored = true
+			}
+		case scanner.TokenIdent:
+			if class && renameClasses {
+				value = cssEscape(id+`-`) + value
+			} else if sel.Len() == 0 && strings.EqualFold(cssUnescape(value), root) {
+				typeEnd = len(value)
+			}
+		case scanner.TokenFunction:
+			depth++
+		case scanner.TokenChar:
+			switch value {
+			case `(`:
+				depth++
+			case `[`:
+				brackets++
+			case `)`:
+				depth--
+			case `]`:
+				brackets--
+			case `{`, `}`, `;`:
+				class = false
+				return flush() + value
+			case `,`:
+				if top {
+					class = false
+					return flush() + value
+				}
+			case `>`, `+`, `~`:
+				compound = compound || top
+			}
+		}
+		class = token.Type == scanner.TokenChar && value == `.` && brackets == 0
+		sel.WriteString(value)
+		return ``
+	})
+	return scoped + flush()
+}
+
+// cssEscape escapes the characters of s that are not valid in a css identifier
+func cssEscape(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '-' || r == '_' || r >= 0x80 ||
+			r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteString(`\3` + string(r) + ` `)
+				continue
+			}
+		default:
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// scopeRef returns the scope id for the replaced id of the root element
+func (w *walker) scopeRef(id string) string {
+	if id == w.scopeReplaced {
+		return w.scopeID
+	}
+	return id
+}
+
+// scopeRefs points the references of an attribute to the replaced id of the
+// root element at the scope id
+func (w *walker) scopeRefs(key string, value string) string {
+	switch {
+	case isIDAttribute(key):
+		return value
+	case key == `begin` || key == `end`:
+		return rewriteTimingRefs(value, w.scopeRef)
+	}
+	return rewriteIDRefs(key, value, w.scopeRef)
+}
+
+// scopeCSSRefs points the id selectors and url(#...) references of a
+// stylesheet to the replaced id of the root element at the scope id
+func (w *walker) scopeCSSRefs(css string) string {
+	return rewriteCSS(css, func(token *scanner.Token, selector bool) string {
+		switch {
+		case token.Type == scanner.TokenURI:
+			return rewriteIDRefs(`style`, token.Value, w.scopeRef)
+		case token.Type == scanner.TokenHash && selector && cssUnescape(token.Value[1:]) == w.scopeReplaced:
+			return `#` + cssEscape(w.scopeID)
+		}
+		return token.Value
+	})
+}
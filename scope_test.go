@@ -0,0 +1,77 @@
+package safesvg
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func Test_CSSScope(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><style>.cls-1,.cls-2 rect{fill:red}@media print{g > .cls-1:hover{fill:blue}}</style><rect class="cls-1 cls-2"/></svg>`)
+	n := 0
+	v := NewValidator()
+	v.SetAllowStyle(true).SetCSSScope(&CSSScope{ID: func() string {
+		n++
+		return `s` + strconv.Itoa(n)
+	}})
+	for _, expected := range []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" id="s1"><style>#s1 .cls-1,#s1 .cls-2 rect{fill:red}@media print{#s1 g &gt; .cls-1:hover{fill:blue}}</style><rect class="cls-1 cls-2"/></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg" id="s2"><style>#s2 .cls-1,#s2 .cls-2 rect{fill:red}@media print{#s2 g &gt; .cls-1:hover{fill:blue}}</style><rect class="cls-1 cls-2"/></svg>`,
+	} {
+		out, err := v.Sanitize(svg)
+		if err != nil {
+			t.Fatalf("Unexptected error %v", err)
+		}
+		if string(out) != expected {
+			t.Errorf("Expected %s, got %s", expected, out)
+		}
+	}
+
+	v.SetCSSScope(&CSSScope{RenameClasses: true, ID: func() string { return `s` }}).SetIDCheck(SeverityError)
+	out, err := v.Sanitize([]byte(`<svg xmlns="http://www.w3.org/2000/svg" id="Layer_1" class="icon"><style>#Layer_1 .cls-1{fill:url(#g)}</style><linearGradient id="g"/><rect class="cls-1" clip-path="url(#Layer_1)"/></svg>`))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" id="s" class="s-icon"><style>#s .s-cls-1{fill:url(#g)}</style><linearGradient id="g"/><rect class="s-cls-1" clip-path="url(#s)"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	// exports of the same tool get distinct scopes
+	v.SetCSSScope(&CSSScope{ID: func() string { return `1a` }})
+	svg = []byte(`<svg xmlns="http://www.w3.org/2000/svg" id="Layer_1"><style>.cls-1{fill:red}</style></svg>`)
+	first, _ := v.Sanitize(svg)
+	second, _ := v.Sanitize(svg)
+	if string(first) == string(second) || strings.Contains(string(first), `Layer_1`) || !strings.Contains(string(first), `id="svg-`) {
+		t.Errorf("Expected distinct random scope ids, got %s and %s", first, second)
+	}
+	if id := randomScopeID(); len(id) != len(`svg-`)+12 || !isXMLName(id) {
+		t.Errorf("Invalid scope id %s", id)
+	}
+	randRead = func([]byte) (int, error) { return 0, errors.New(`no entropy`) }
+	defer func() { randRead = rand.Read }()
+	if first, second := randomScopeID(), randomScopeID(); first == second || !isXMLName(first) {
+		t.Errorf("Expected distinct scope ids, got %s and %s", first, second)
+	}
+}
+
+func Test_ScopeCSS(t *testing.T) {
+	for css, expected := range map[string]string{
+		`.a:is(.b,.c) rect,[class~=d]{fill:red}`:                     `#s .s-a:is(.s-b,.s-c) rect,#s [class~=d]{fill:red}`,
+		`@keyframes spin{from{opacity:0}50%{opacity:1}}.a{fill:red}`: `@keyframes spin{from{opacity:0}50%{opacity:1}}#s .s-a{fill:red}`,
+		`@supports (display:grid){.a,rect{fill:red}}`:                `@supports (display:grid){#s .s-a,#s rect{fill:red}}`,
+		`@media print{@supports (color:red){.a{fill:red}}}`:          `@media print{@supports (color:red){#s .s-a{fill:red}}}`,
+		`#s .a,svg#s:hover rect{fill:red}`:                           `#s .s-a,svg#s:hover rect{fill:red}`,
+		`svg{fill:red}`:                                              `svg#s,#s svg{fill:red}`,
+		`svg > g, SVG:hover .a{fill:red}`:                            `svg#s > g,#s svg > g, SVG#s:hover .s-a,#s SVG:hover .s-a{fill:red}`,
+		`g svg,rect #s{fill:red}`:                                    `#s g svg,#s rect #s{fill:red}`,
+	} {
+		if scoped := scopeCSS(css, `s`, `svg`, true); scoped != expected {
+			t.Errorf("Expected %s, got %s", expected, scoped)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package safesvg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// pdfSniffLength is how far from the start pdf readers look for the %PDF- header
+const pdfSniffLength = 1024
+
+var polyglotSignatures = []struct {
+	name      string
+	signature []byte
+	within    int // 0 for anywhere
+}{
+	{`pdf`, []byte(`%PDF-`), pdfSniffLength},
+	{`zip`, []byte("PK\x03\x04"), 0},
+	{`zip`, []byte("PK\x05\x06"), 0},
+}
+
+// Sniffed describes the data checked by Sniff
+type Sniffed struct {
+	// Compressed is true for gzip compressed svgz data
+	Compressed bool
+	// Root is the name of the root element
+	Root xml.Name
+	// ContentType is the content type browsers would sniff for the
+	// (decompressed) data if it was served without one
+	ContentType string
+	// ServeAsSVG is true when the data is safe to serve with the
+	// image/svg+xml content type, i.e. when Sniff returned no error
+	ServeAsSVG bool
+}
+
+// Sniff checks that b really is a svg document, as opposed to a file of
+// another type containing svg: nothing but a byte order mark and whitespace
+// before the xml prolog, a single svg root element in the svg namespace,
+// nothing after it and no pdf or zip signature a reader of those formats
+// would find. A nil error and ServeAsSVG mean the data can be served with
+// the image/svg+xml content type, though it still needs to be validated.
+// Data larger than DefaultMaxDecompressedSize is rejected.
+func Sniff(b []byte) (*Sniffed, error) {
+	return Validator{}.SniffReader(bytes.NewReader(b))
+}
+
+// SniffReader is Sniff reading the data from an io.Reader
+func SniffReader(r io.Reader) (*Sniffed, error) {
+	return Validator{}.SniffReader(r)
+}
+
+// Sniff is the Sniff function with the decompression limits of the validator,
+// the size limit also applies to uncompressed data
+func (vld Validator) Sniff(b []byte) (*Sniffed, error) {
+	return vld.SniffReader(bytes.NewReader(b))
+}
+
+// SniffReader is Sniff reading the data from an io.Reader
+func (vld Validator) SniffReader(r io.Reader) (*Sniffed, error) {
+	sniffed := &Sniffed{}
+	var head [2]byte
+	n, _ := io.ReadFull(r, head[:])
+	r = io.MultiReader(bytes.NewReader(head[:n]), r)
+	maxSize := vld.maxDecompressedSize
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDecompressedSize
+	}
+	if bytes.HasPrefix(head[:n], gzipMagic) {
+		gz, err := vld.gunzip(r)
+		if err != nil {
+			return sniffed, fmt.Errorf("%w: %v", ErrNotSVG, err)
+		}
+		sniffed.Compressed = true
+		r = gz
+	}
+	data, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return sniffed, err
+	}
+	if int64(len(data)) > maxSize {
+		return sniffed, fmt.Errorf("%w: larger than %d bytes", ErrNotSVG, maxSize)
+	}
+	sniffed.ContentType = http.DetectContentType(data)
+	if err = sniffLeadingBytes(data); err != nil {
+		return sniffed, err
+	}
+	for _, poly := range polyglotSignatures {
+		search := data
+		if poly.within > 0 && len(search) > poly.within {
+			search = search[:poly.within]
+		}
+		if bytes.Contains(search, poly.signature) {
+			return sniffed, fmt.Errorf("%w: %s signature", ErrPolyglot, poly.name)
+		}
+	}
+	w := &walker{vld: &vld}
+	t, err := w.newTokenizer(bytes.NewReader(data))
+	if err != nil {
+		return sniffed, fmt.Errorf("%w: %v", ErrNotSVG, err)
+	}
+	var depth int
+	for {
+		to, err := t.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sniffed, fmt.Errorf("%w: %v", ErrNotSVG, err)
+		}
+		switch v := to.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				if len(sniffed.Root.Local) > 0 {
+					return sniffed, fmt.Errorf("%w: element <%s> after the root element", ErrNotSVG, v.Name.Local)
+				}
+				sniffed.Root = v.Name
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			if depth == 0 && len(bytes.TrimSpace(v)) > 0 {
+				return sniffed, fmt.Errorf("%w: text outside of the root element", ErrNotSVG)
+			}
+		}
+	}
+	if sniffed.Root.Local != `svg` || sniffed.Root.Space != nsSVG {
+		return sniffed, fmt.Errorf("%w: root element is <%s> in namespace %q", ErrNotSVG, sniffed.Root.Local, sniffed.Root.Space)
+	}
+	sniffed.ServeAsSVG = true
+	return sniffed, nil
+}
+
+// sniffLeadingBytes checks that the data starts with markup, after an optional
+// byte order mark and, without xml declaration, whitespace
+func sniffLeadingBytes(data []byte) error {
+	for _, bom := range [][]byte{bomUTF8, bomUTF16LE, bomUTF16BE} {
+		if bytes.HasPrefix(data, bom) {
+			data = data[len(bom):]
+			if len(bom) == 2 {
+				// checked by the decoder after conversion
+				return nil
+			}
+			break
+		}
+	}
+	if bytes.HasPrefix(data, utf16LE) || bytes.HasPrefix(data, utf16BE) {
+		return nil
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	switch {
+	case len(trimmed) == 0 || trimmed[0] != '<':
+		return fmt.Errorf("%w: %.16q before the markup", ErrNotSVG, trimmed)
+	case len(trimmed) < len(data) && bytes.HasPrefix(trimmed, []byte(`<?xml`)) &&
+		len(trimmed) > 5 && strings.ContainsRune(" \t\r\n?", rune(trimmed[5])):
+		return fmt.Errorf("%w: whitespace before the xml declaration", ErrNotSVG)
+	}
+	return nil
+}
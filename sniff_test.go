@@ -0,0 +1,40 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_Sniff(t *testing.T) {
+	for svg, expected := range map[string]error{
+		`<svg xmlns="http://www.w3.org/2000/svg"/>`:                                                    nil,
+		"\xEF\xBB\xBF<?xml version=\"1.0\"?>\n<!-- a -->\n<svg xmlns=\"http://www.w3.org/2000/svg\"/>": nil,
+		"GIF89a<svg xmlns=\"http://www.w3.org/2000/svg\"/>":                                            ErrNotSVG,
+		"\n<?xml version=\"1.0\"?><svg xmlns=\"http://www.w3.org/2000/svg\"/>":                         ErrNotSVG,
+		`<html><body><svg xmlns="http://www.w3.org/2000/svg"/></body></html>`:                          ErrNotSVG,
+		`<svg><script>alert(1)</script></svg>`:                                                         ErrNotSVG,
+		`<svg xmlns="http://www.w3.org/2000/svg"/><svg xmlns="http://www.w3.org/2000/svg"/>`:           ErrNotSVG,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"/>\x00\x01binary":                                    ErrNotSVG,
+		`<svg xmlns="http://www.w3.org/2000/svg"><!-- %PDF-1.4 --></svg>`:                              ErrPolyglot,
+		"<svg xmlns=\"http://www.w3.org/2000/svg\"><!-- PK\x03\x04 --></svg>":                          ErrPolyglot,
+	} {
+		sniffed, err := Sniff([]byte(svg))
+		if !errors.Is(err, expected) || sniffed.ServeAsSVG != (expected == nil) {
+			t.Errorf("%q: expected %v, got %v (%+v)", svg, expected, err, sniffed)
+		}
+	}
+	sniffed, err := Sniff(gzipBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`)))
+	if err != nil || !sniffed.Compressed || sniffed.Root.Local != `svg` {
+		t.Errorf("Unexpected result %+v, error %v", sniffed, err)
+	}
+	v := NewValidator()
+	v.SetDecompressionLimits(64, 0)
+	for _, svg := range [][]byte{
+		[]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="10" height="10"/></svg>`),
+		gzipBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><rect width="10" height="10"/></svg>`)),
+	} {
+		if sniffed, err := v.Sniff(svg); err == nil || sniffed.ServeAsSVG {
+			t.Errorf("Expected a size limit error, got %+v", sniffed)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package safesvg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/gorilla/css/scanner"
+)
+
+// htmlElementNames are the svg elements html has an element of the same name
+// for, a type selector for them also matches the html page
+var htmlElementNames = map[string]struct{}{
+	`a`:      {},
+	`audio`:  {},
+	`canvas`: {},
+	`font`:   {},
+	`iframe`: {},
+	`script`: {},
+	`style`:  {},
+	`title`:  {},
+	`video`:  {},
+}
+
+// outsidePseudoClasses match elements outside of an inlined svg
+var outsidePseudoClasses = map[string]struct{}{
+	`root`:          {},
+	`host`:          {},
+	`host(`:         {},
+	`host-context(`: {},
+	`scope`:         {},
+}
+
+// SetAllowStyle allows <style> elements. Their css is checked by
+// ValidateStyle and the css rules, and selectors that could match elements
+// outside of the svg once it is inlined into html are rejected: the
+// universal selector, :root, :host, :scope and type selectors for elements
+// that are not whitelisted or also exist in html, e.g. body, html or a.
+// Unless Sanitize scopes the stylesheets with SetCSSScope, every selector
+// must also start with the id of the root element: with <svg id="logo">,
+// "#logo rect" and "svg#logo > .cls-1" are accepted but "rect.cls-1", "g rect"
+// or ":hover" are rejected, as they would match the other svgs or the
+// elements of the page. The text of a <style> element is checked as a whole,
+// elements inside it are rejected, or dropped by Sanitize. Without it
+// <style> is rejected unless whitelisted by hand.
+func (vld *Validator) SetAllowStyle(allow bool) *Validator {
+	vld.allowStyle = allow
+	if allow {
+		vld.WhitelistElements(`style`)
+		vld.WhitelistElementAttributes(`style`, `type`, `media`)
+	} else {
+		vld.BlacklistElements(`style`)
+	}
+	return vld
+}
+
+// checkSelectors reports the first selector of the stylesheet css that could
+// match elements outside of the svg. scoped is true when the selectors are
+// scoped to the root element, otherwise their first compound selector must
+// hold the id selector of the root element rootID. css that cannot be
+// tokenized to the end fails, as browsers recover and apply the rules after
+// e.g. an unclosed string.
+func (vld *Validator) checkSelectors(css string, rootID string, scoped bool) (err error) {
+	var (
+		prev     string          // last char token of the selector
+		brackets int             // depth in attribute selectors
+		skip     int             // depth in functions without selectors
+		text     strings.Builder // the selector
+		anchored bool            // the selector starts with the root id
+		compound bool            // past the first compound selector
+	)
+	reset := func() {
+		text.Reset()
+		anchored = false
+		compound = false
+	}
+	end := func() {
+		if selector := strings.TrimSpace(text.String()); err == nil && len(selector) > 0 && !anchored && !scoped {
+			err = fmt.Errorf("%w: %s does not start with the id of the root element, scope the stylesheet with SetCSSScope", ErrUnallowedCSSSelector, selector)
+		}
+		reset()
+	}
+	rewriteCSS(css, func(token *scanner.Token, selector bool) string {
+		if err == nil && token.Type == scanner.TokenError {
+			err = fmt.Errorf("%w: %s", ErrUnallowedCSSSelector, token.Value)
+		}
+		if err != nil || !selector {
+			prev = ``
+			reset()
+			return ``
+		}
+		after := prev
+		prev = ``
+		if token.Type == scanner.TokenChar && (token.Value == `,` || token.Value == `{`) && brackets == 0 && skip == 0 {
+			end()
+			prev = token.Value
+			return ``
+		}
+		if brackets == 0 && skip == 0 && text.Len() > 0 && strings.TrimSpace(text.String()) != `` {
+			switch {
+			case token.Type == scanner.TokenS, token.Type == scanner.TokenComment:
+				compound = true
+			case token.Type == scanner.TokenChar && strings.Contains(`>+~`, token.Value):
+				compound = true
+			}
+		}
+		text.WriteString(token.Value)
+		switch token.Type {
+		case scanner.TokenChar:
+			switch token.Value {
+			case `[`:
+				brackets++
+			case `]`:
+				brackets--
+			case `(`:
+				skip++
+			case `)`:
+				if skip > 0 {
+					skip--
+				}
+			case `*`:
+				if brackets == 0 && skip == 0 {
+					err = fmt.Errorf("%w: *", ErrUnallowedCSSSelector)
+				}
+			case `}`, `;`:
+				reset()
+			}
+			prev = token.Value
+		case scanner.TokenHash:
+			if brackets == 0 && skip == 0 && !compound && len(rootID) > 0 && cssUnescape(token.Value[1:]) == rootID {
+				anchored = true
+			}
+		case scanner.TokenFunction:
+			name := strings.ToLower(token.Value)
+			if _, ok := outsidePseudoClasses[name]; ok && after == `:` {
+				err = fmt.Errorf("%w: :%s", ErrUnallowedCSSSelector, token.Value)
+			}
+			skip++
+		case scanner.TokenIdent:
+			if brackets > 0 || skip > 0 {
+				break
+			}
+			name := strings.ToLower(token.Value)
+			switch after {
+			case `.`:
+			case `:`:
+				if _, ok := outsidePseudoClasses[name]; ok {
+					err = fmt.Errorf("%w: :%s", ErrUnallowedCSSSelector, token.Value)
+				}
+			default:
+				_, html := htmlElementNames[name]
+				if _, ok := vld.whiteListElements[name]; !ok || html {
+					err = fmt.Errorf("%w: %s", ErrUnallowedCSSSelector, token.Value)
+				}
+			}
+		}
+		return ``
+	})
+	return
+}
+
+// stylesheet checks the text of a <style> element at its end tag, so that
+// comments and CDATA sections cannot split a rule between the checks, and
+// writes it rewritten in sanitize mode. Sanitize drops a stylesheet failing
+// a check.
+func (w *walker) stylesheet() (err error) {
+	if len(w.css) == 0 {
+		return nil
+	}
+	if w.vld.offline && w.networkReference(cssNetworkReferences(w.css)...) {
+		return nil
+	}
+	css := string(w.css)
+	if fn, ok := w.vld.innerTextValidator[`style`]; ok {
+		err = fn(w.css)
+	}
+	if err == nil && w.vld.cssRules != nil {
+		err = w.vld.cssRules.check(css, false)
+	}
+	if err == nil && w.vld.allowStyle {
+		err = w.vld.checkSelectors(css, w.rootID, w.sanitize() && w.vld.cssScope != nil)
+	}
+	if err != nil {
+		if w.sanitize() {
+			return nil
+		}
+		return err
+	}
+	if w.vld.inlineSafe && w.sanitize() {
+		css = rewriteCSSIDs(css, prefixClobberingName)
+	}
+	if len(w.vld.idPrefix) > 0 && w.sanitize() {
+		css = rewriteCSSIDs(css, w.vld.prefixID)
+	}
+	if len(w.scopeReplaced) > 0 && w.sanitize() {
+		css = w.scopeCSSRefs(css)
+	}
+	if w.vld.idCheck != SeverityIgnore {
+		// after the rewrites, like the ids of the attributes
+		w.addIDRefs(`style`, css)
+	}
+	if w.vld.cssScope != nil && w.sanitize() {
+		css = scopeCSS(css, w.scopeID, w.scopeElem, w.vld.cssScope.RenameClasses)
+	}
+	if w.sanitize() {
+		w.out.CharData(xml.CharData(css))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package safesvg
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_AllowStyle(t *testing.T) {
+	const (
+		head = `<svg xmlns="http://www.w3.org/2000/svg" id="logo"><style type="text/css">`
+		tail = `</style><rect class="cls-1"/></svg>`
+	)
+	v := NewValidator()
+	if err := v.Validate([]byte(head + `.cls-1{fill:red}` + tail)); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+	v.SetAllowStyle(true)
+	for css, expected := range map[string]error{
+		`#logo .cls-1{isolation:isolate}#logo .cls-2,#logo>.cls-3{fill:url(#g)}`: nil,
+		`#logo rect.cls-1 > circle:hover, svg#logo g#a::before{fill:red}`:        nil,
+		`rect.cls-1{fill:red}`:                                 ErrUnallowedCSSSelector,
+		`.navbar,.btn-primary{display:none}`:                   ErrUnallowedCSSSelector,
+		`.a #logo{fill:red}`:                                   ErrUnallowedCSSSelector,
+		`rect #logo{fill:red}`:                                 ErrUnallowedCSSSelector,
+		`[class*=cls]{fill:red}`:                               ErrUnallowedCSSSelector,
+		`:hover{display:none}`:                                 ErrUnallowedCSSSelector,
+		`:first-child{display:none}`:                           ErrUnallowedCSSSelector,
+		`::selection{color:red}`:                               ErrUnallowedCSSSelector,
+		`.cls-1,[id]:hover{fill:red}`:                          ErrUnallowedCSSSelector,
+		`#logo .cls-1[class]:hover,#logo::selection{fill:red}`: nil,
+		`@media (min-width:100px){#logo .cls-1{fill:red}}`:     nil,
+		`@keyframes spin{from{opacity:0}to{opacity:1}}`:        nil,
+		`body{display:none}`:                                   ErrUnallowedCSSSelector,
+		`html .cls-1{fill:red}`:                                ErrUnallowedCSSSelector,
+		`*{display:none}`:                                      ErrUnallowedCSSSelector,
+		`.cls-1, :root{color:red}`:                             ErrUnallowedCSSSelector,
+		`:host{color:red}`:                                     ErrUnallowedCSSSelector,
+		`a{color:red}`:                                         ErrUnallowedCSSSelector,
+		`@media print{div{display:none}}`:                      ErrUnallowedCSSSelector,
+		`@import "a.css";`:                                     ErrUnallowedCSSAttribute,
+		`svg{display:none}`:                                    ErrUnallowedCSSSelector,
+		`.cls-1,g rect{fill:red}`:                              ErrUnallowedCSSSelector,
+		`#logo rect,#logo .cls-1{fill:red}`:                    nil,
+		`#logo rect,#a rect{fill:red}`:                         ErrUnallowedCSSSelector,
+		"#a rect{fill:red} \"x\n{} body{display:none}":         ErrUnallowedCSSSelector,
+		`#a rect{fill:red} /* body{display:none}`:              ErrUnallowedCSSSelector,
+	} {
+		if err := v.Validate([]byte(head + css + tail)); !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, got %v", css, expected, err)
+		}
+	}
+
+	out, err := v.Sanitize([]byte(head + `body{display:none}` + tail))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" id="logo"><style type="text/css"/><rect class="cls-1"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	out, err = v.Sanitize([]byte(head + "#a rect{fill:red} \"x\n{} body{display:none}" + tail))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+
+	v.SetCSSScope(&CSSScope{ID: func() string { return `s` }})
+	out, err = v.Sanitize([]byte(head + `svg{display:none}` + tail))
+	if err != nil {
+		t.Fatalf("Unexptected error %v", err)
+	}
+	expected = `<svg xmlns="http://www.w3.org/2000/svg" id="s"><style type="text/css">svg#s,#s svg{display:none}</style><rect class="cls-1"/></svg>`
+	if string(out) != expected {
+		t.Errorf("Expected %s, got %s", expected, out)
+	}
+	v.SetCSSScope(nil)
+
+	v.SetAllowStyle(false)
+	if err := v.Validate([]byte(head + `.cls-1{fill:red}` + tail)); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+}
+
+func Test_StyleSplit(t *testing.T) {
+	const head = `<svg xmlns="http://www.w3.org/2000/svg" id="r">`
+	v := NewValidator()
+	v.SetAllowStyle(true)
+	for svg, expected := range map[string]error{
+		head + `<style>@media all<!---->{body{display:none}}</style></svg>`:       ErrUnallowedCSSSelector,
+		head + `<style>#r .a{fill:red}<![CDATA[]]>body{fill:red}</style></svg>`:   ErrUnallowedCSSSelector,
+		head + `<style><g/>:hover{display:none}</style></svg>`:                    ErrInvalidElement,
+		head + `<style>#r .a{fill:red}<?xml-stylesheet href="#a"?></style></svg>`: ErrInvalidProcInst,
+		head + `<style>#r .a{fill:red}<!---->#r .b{fill:blue}</style></svg>`:      nil,
+	} {
+		if err := v.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, got %v", svg, expected, err)
+		}
+	}
+	for svg, expected := range map[string]string{
+		head + `<style>@media all<!---->{body{display:none}}</style></svg>`: head + `<style/></svg>`,
+		head + `<style><g/>#r .a{fill:red}</style></svg>`:                   head + `<style>#r .a{fill:red}</style></svg>`,
+		head + `<style>#r .a{<!---->fill:red}</style></svg>`:                head + `<style>#r .a{fill:red}</style></svg>`,
+	} {
+		out, err := v.Sanitize([]byte(svg))
+		if err != nil || string(out) != expected {
+			t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+		}
+	}
+
+	v.SetOffline(true)
+	svg := []byte(head + `<style>#r .a{background:u<!---->rl(http://evil.example/x)}</style></svg>`)
+	if err := v.Validate(svg); !errors.Is(err, ErrNetworkReference) {
+		t.Errorf("Expected %v, got %v", ErrNetworkReference, err)
+	}
+	out, err := v.Sanitize([]byte(head + `<style><g/>#r .a{background:url(http://evil.example/x)}</style></svg>`))
+	if expected := head + `<style/></svg>`; err != nil || string(out) != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+	}
+	v.SetOffline(false)
+
+	v.SetCSSScope(&CSSScope{ID: func() string { return `s` }})
+	out, err = v.Sanitize([]byte(head + `<style><g/>.a{fill:red}<!---->.b{fill:blue}</style></svg>`))
+	if expected := `<svg xmlns="http://www.w3.org/2000/svg" id="s"><style>#s .a{fill:red}#s .b{fill:blue}</style></svg>`; err != nil || string(out) != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+	}
+}
@@ -0,0 +1,95 @@
+package safesvg
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// DefaultMaxDecompressedSize is the default size limit of decompressed svgz data
+	DefaultMaxDecompressedSize int64 = 32 << 20
+	// DefaultMaxCompressionRatio is the default limit of the decompressed to
+	// compressed size ratio of svgz data
+	DefaultMaxCompressionRatio int64 = 100
+
+	// the compression ratio is only checked beyond this size, small
+	// documents compress too unevenly for the ratio to mean anything
+	compressionRatioThreshold = 1 << 20
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SetDecompressionLimits sets the maximum decompressed size and compression
+// ratio of gzip compressed (svgz) input, 0 keeps the default
+func (vld *Validator) SetDecompressionLimits(maxSize int64, maxRatio int64) *Validator {
+	vld.maxDecompressedSize = maxSize
+	vld.maxCompressionRatio = maxRatio
+	return vld
+}
+
+// ValidateFile validates the svg or svgz file with the given name
+func (vld Validator) ValidateFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return vld.ValidateReader(f)
+}
+
+// gunzip returns a reader decompressing the gzip data of r within the limits
+func (vld *Validator) gunzip(r io.Reader) (io.Reader, error) {
+	compressed := &countingReader{r: r}
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, err
+	}
+	gz.Multistream(false)
+	b := &bombReader{
+		r:          gz,
+		compressed: compressed,
+		maxSize:    vld.maxDecompressedSize,
+		maxRatio:   vld.maxCompressionRatio,
+	}
+	if b.maxSize <= 0 {
+		b.maxSize = DefaultMaxDecompressedSize
+	}
+	if b.maxRatio <= 0 {
+		b.maxRatio = DefaultMaxCompressionRatio
+	}
+	return b, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// bombReader stops reading decompressed data beyond the size and ratio limits
+type bombReader struct {
+	r          io.Reader
+	compressed *countingReader
+	n          int64
+	maxSize    int64
+	maxRatio   int64
+}
+
+func (b *bombReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	b.n += int64(n)
+	if b.n > b.maxSize {
+		return 0, fmt.Errorf("%w: decompressed size exceeds %d bytes", ErrDecompressionBomb, b.maxSize)
+	}
+	if b.n > compressionRatioThreshold && b.n > b.compressed.n*b.maxRatio {
+		return 0, fmt.Errorf("%w: compression ratio exceeds %d", ErrDecompressionBomb, b.maxRatio)
+	}
+	return n, err
+}
@@ -0,0 +1,42 @@
+package safesvg
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func gzipBytes(b []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(b)
+	gz.Close()
+	return buf.Bytes()
+}
+
+func Test_SVGZ(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><path d="M0 0h24"/></svg>`)
+	v := NewValidator()
+	if err := v.Validate(gzipBytes(svg)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	out, err := v.Sanitize(gzipBytes(svg))
+	if err != nil || !bytes.Equal(out, svg) {
+		t.Errorf("Unexpected output %s, error %v", out, err)
+	}
+	if err = v.Validate(gzipBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><script/></svg>`))); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+
+	bomb := gzipBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><!--` + strings.Repeat(` `, 4<<20) + `--></svg>`))
+	if err = v.Validate(bomb); !errors.Is(err, ErrDecompressionBomb) {
+		t.Errorf("Expected %v, got %v", ErrDecompressionBomb, err)
+	}
+	v.SetDecompressionLimits(1<<10, 0)
+	large := gzipBytes([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><!--` + strings.Repeat(` `, 2<<10) + `--></svg>`))
+	if err = v.Validate(large); !errors.Is(err, ErrDecompressionBomb) {
+		t.Errorf("Expected %v, got %v", ErrDecompressionBomb, err)
+	}
+}
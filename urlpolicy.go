@@ -0,0 +1,181 @@
+package safesvg
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// URLPolicy restricts the urls that may be referenced by href, xlink:href and
+// url(...) in attribute values. Fragment references ("#id") and data: urls
+// (checked by the href validator) are always allowed.
+type URLPolicy struct {
+	// Schemes lists the allowed schemes of absolute urls, e.g. "https".
+	// Absolute urls are rejected when empty.
+	Schemes []string
+	// Hosts lists the allowed hosts of absolute urls. A leading "*." matches
+	// any subdomain, e.g. "*.cdn.example". Any host is allowed when empty.
+	Hosts []string
+	// AllowRelative allows relative references such as "img/a.png" or "/a.png".
+	// Scheme-relative urls ("//host/a.png") are always rejected.
+	AllowRelative bool
+	// RewriteURL is called in sanitize mode for every allowed url that is not
+	// a fragment or data: url, and its result replaces the original value.
+	// attr is the lowercased attribute name, e.g. "xlink:href" or "fill".
+	// The result is checked against the policy again. An error, or a result
+	// the policy does not allow, aborts Sanitize with a *RewriteURLError.
+	RewriteURL func(attr, value string) (string, error)
+}
+
+// RewriteURLError is returned by Sanitize when RewriteURL fails or returns a
+// url the policy does not allow
+type RewriteURLError struct {
+	Attr string
+	URL  string
+	Err  error
+}
+
+func (e *RewriteURLError) Error() string {
+	return `[svg] rewrite url ` + e.Attr + `="` + e.URL + `": ` + e.Err.Error()
+}
+
+func (e *RewriteURLError) Unwrap() error {
+	return e.Err
+}
+
+var cssURLRegexp = regexp.MustCompile(`(?i)url\(\s*(['"]?)([^'")]*)(['"]?)\s*\)`)
+
+func isLocalURL(value string) bool {
+	value = strings.TrimSpace(value)
+	return len(value) == 0 || value[0] == '#' ||
+		(len(value) > 5 && strings.EqualFold(value[0:5], `data:`))
+}
+
+// urlReplacer removes the tabs and newlines browsers ignore in urls and turns
+// backslashes into slashes, as browsers do for http(s) and relative urls
+var urlReplacer = strings.NewReplacer("\t", ``, "\n", ``, "\r", ``, `\`, `/`)
+
+// Allow reports an error if the url is not allowed by the policy
+func (p *URLPolicy) Allow(value string) error {
+	value = strings.TrimSpace(value)
+	if isLocalURL(value) {
+		return nil
+	}
+	normalized := urlReplacer.Replace(value)
+	u, err := url.Parse(normalized)
+	if err != nil {
+		return fmt.Errorf(`%w: %s`, ErrUnallowedURL, value)
+	}
+	if len(u.Scheme) == 0 {
+		if len(u.Host) > 0 || strings.HasPrefix(normalized, `//`) || !p.AllowRelative {
+			return fmt.Errorf(`%w: %s`, ErrUnallowedURL, value)
+		}
+		return nil
+	}
+	if !containsFold(p.Schemes, u.Scheme) || !p.allowHost(u.Hostname()) {
+		return fmt.Errorf(`%w: %s`, ErrUnallowedURL, value)
+	}
+	return nil
+}
+
+func (p *URLPolicy) allowHost(host string) bool {
+	if len(p.Hosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range p.Hosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, `*.`) {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// apply checks the value of an attribute against the policy and, if rewrite is
+// true, passes the referenced urls through RewriteURL
+func (p *URLPolicy) apply(attr string, value string, rewrite bool) (string, error) {
+	if isHrefAttribute(attr) {
+		if err := p.Allow(value); err != nil {
+			return value, err
+		}
+		if !rewrite || p.RewriteURL == nil || isLocalURL(value) {
+			return value, nil
+		}
+		return p.rewrite(attr, strings.TrimSpace(value))
+	}
+	if !strings.Contains(value, `(`) {
+		return value, nil
+	}
+	// the tokenizer also finds escaped urls and image-set("...") strings
+	var urls []string
+	if err := cssReferences(value, func(_ string, u string) {
+		if !isLocalURL(u) {
+			urls = append(urls, u)
+		}
+	}); err != nil {
+		// the urls after a tokenizer error are not found
+		return value, fmt.Errorf("%w: %s cannot be checked", ErrUnallowedURL, strings.TrimSpace(value))
+	}
+	for _, u := range urls {
+		if err := p.Allow(u); err != nil {
+			return value, err
+		}
+	}
+	if !rewrite || p.RewriteURL == nil || len(urls) == 0 {
+		return value, nil
+	}
+	var (
+		err       error
+		rewritten int
+	)
+	value = cssURLRegexp.ReplaceAllStringFunc(value, func(m string) string {
+		sub := cssURLRegexp.FindStringSubmatch(m)
+		if err != nil || isLocalURL(sub[2]) {
+			return m
+		}
+		var u string
+		if u, err = p.rewrite(attr, strings.TrimSpace(sub[2])); err != nil {
+			return m
+		}
+		rewritten++
+		return `url(` + sub[1] + u + sub[3] + `)`
+	})
+	if err == nil && rewritten != len(urls) {
+		// escaped or string urls cannot be rewritten in place
+		err = fmt.Errorf(`%w: %s cannot be rewritten`, ErrUnallowedURL, strings.TrimSpace(value))
+	}
+	return value, err
+}
+
+// rewrite passes an allowed url through RewriteURL and checks the result
+func (p *URLPolicy) rewrite(attr string, value string) (string, error) {
+	rewritten, err := p.RewriteURL(attr, value)
+	if err == nil {
+		err = p.Allow(rewritten)
+	}
+	if err != nil {
+		return value, &RewriteURLError{Attr: attr, URL: value, Err: err}
+	}
+	return rewritten, nil
+}
+
+func isHrefAttribute(key string) bool {
+	return key == `href` || strings.HasSuffix(key, `:href`)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
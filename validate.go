@@ -3,25 +3,51 @@ package safesvg
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
-	"regexp"
 	"strings"
 )
 
-// Validator is a struct with private variables for storing the whitelists
+// Validator is a struct with private variables for storing the whitelists.
+// The whitelists map the lowercased names to their canonical spelling.
 type Validator struct {
-	whiteListElements   map[string]struct{}
-	whiteListAttributes map[string]struct{}
+	whiteListElements   map[string]string
+	whiteListAttributes map[string]string
+	elementAttributes   map[string]map[string]string // attributes allowed on some elements only
 	innerTextValidator  map[string]func([]byte) error
 	attrValueValidator  map[string]func(string) error
+	urlPolicy           *URLPolicy
+	offline             bool
+	idCheck             Severity
+	inlineSafe          bool
+	idPrefix            string
+	rootCheck           RootCheck
+	doctypePolicy       DoctypePolicy
+	allowXMLStylesheet  bool
+	requireUTF8         bool
+	maxDecompressedSize int64
+	maxCompressionRatio int64
+	strictCase          bool
+	warningHandler      func(error)
+	parser              Parser
+	cssRules            *CSSRules
+	editorNamespaces    bool
+	allowStyle          bool
+	cssScope            *CSSScope
+	fragmentHrefs       bool
+	// set by the validator setters, policy files cannot hold custom validators
+	customValidators bool
 }
 
+//go:generate go run ./internal/genwhitelist -out default.go
+
 // NewValidator creates a new validator with default whitelists
 func NewValidator() Validator {
 	vld := Validator{
-		whiteListElements:   map[string]struct{}{},
-		whiteListAttributes: map[string]struct{}{},
+		whiteListElements:   map[string]string{},
+		whiteListAttributes: map[string]string{},
+		elementAttributes:   map[string]map[string]string{},
 		innerTextValidator: map[string]func([]byte) error{
 			`style`: ValidateStyle,
 		},
@@ -29,133 +55,337 @@ func NewValidator() Validator {
 			`href`: validateHref,
 		},
 	}
-	for k, v := range svg_elements {
-		vld.whiteListElements[k] = v
-	}
-	for k, v := range svg_attributes {
-		vld.whiteListAttributes[k] = v
-	}
+	vld.WhitelistElements(mapKeys(svg_elements)...)
+	vld.WhitelistElements(mapKeys(svg_filters_elements)...)
+	vld.WhitelistElements(defaultExtraElements...)
+	vld.BlacklistElements(defaultExcludedElements...)
+	vld.WhitelistAttributes(mapKeys(svg_attributes)...)
+	vld.WhitelistAttributes(mapKeys(xml_attributes)...)
+	vld.WhitelistAttributes(defaultExtraAttributes...)
 	return vld
 }
 
+// defaultExtraElements are not in DOMPurify's profiles but allowed by
+// default, the validator limits the references of <use>
+var defaultExtraElements = []string{`use`}
+
+// defaultExtraAttributes are not in DOMPurify's profiles but allowed by
+// default, baseProfile is common on the root of svg 1.1 documents
+var defaultExtraAttributes = []string{`baseProfile`}
+
+// defaultExcludedElements are in DOMPurify's profiles but not allowed by
+// default: <a> links out of the svg, <style> needs its css checked and
+// <feImage> fetches images
+var defaultExcludedElements = []string{`a`, `style`, `feImage`}
+
 // Validate validates a slice of bytes containing the svg data
 func (vld Validator) Validate(b []byte) error {
 	r := bytes.NewReader(b)
 	return vld.ValidateReader(r)
 }
 
-var (
-	entitySystemRegexp = regexp.MustCompile(`(?i)<!ENTITY\b`)
-	doctypeBytes       = []byte(`DOCTYPE`)
-)
-
 // ValidateReader validates svg data from an io.Reader interface
 func (vld Validator) ValidateReader(r io.Reader) error {
-	t := xml.NewDecoder(r)
-	var (
-		to    xml.Token
-		err   error
-		elem  string
-		id    string
-		id4El string
-		usec  = useRefs{}
-		root  = &useRef{}
-	)
+	return vld.walk(r, nil)
+}
 
+// walker holds the state of a single pass over the svg tokens. In sanitize
+// mode (out != nil) invalid elements and attributes are dropped from the
+// output instead of failing the pass.
+type walker struct {
+	vld   *Validator
+	out   *xmlWriter
+	skip  int // depth inside a dropped element
+	depth int
+	elem  string
+	id    string
+	id4El string
+	usec  useRefs
+	root  *useRef
+	// network lists the fetching constructs found in offline mode
+	network     []string
+	ids         map[string]struct{}
+	idRefs      []string
+	started     bool // a token was processed
+	rootSeen    bool
+	doctypeSeen bool
+	// id of the root element the stylesheets are scoped to, and the id it
+	// replaced
+	scopeID       string
+	scopeReplaced string
+	scopeElem     string // name of the root element
+	rootID        string // id attribute of the root element
+	// text of the open <style> element, checked as a whole at its end
+	css     []byte
+	inStyle bool
+}
+
+// newWalker returns a walker for a pass of vld, sanitizing into out unless nil
+func (vld *Validator) newWalker(out *xmlWriter) *walker {
+	return &walker{
+		vld:  vld,
+		out:  out,
+		usec: useRefs{},
+		root: &useRef{},
+	}
+}
+
+func (vld *Validator) walk(r io.Reader, out *xmlWriter) error {
+	w := vld.newWalker(out)
+	t, err := w.newTokenizer(r)
+	if err != nil {
+		return err
+	}
 	for {
-		to, err = t.Token()
+		to, err := t.Token()
 		if err != nil {
 			if err == io.EOF || err.Error() == "EOF" {
 				break
 			}
 			return err
 		}
+		if err = w.token(to); err != nil {
+			return err
+		}
+		w.started = true
+	}
+	if !w.rootSeen && vld.rootCheck != RootCheckNone {
+		return fmt.Errorf("%w: missing root element", ErrInvalidDocument)
+	}
+	if len(w.network) > 0 {
+		return fmt.Errorf("%w: %s", ErrNetworkReference, strings.Join(w.network, `, `))
+	}
+	if vld.idCheck != SeverityIgnore {
+		if err := w.checkIDRefs(); err != nil {
+			return err
+		}
+	}
+	if out != nil {
+		return out.Flush()
+	}
+	return nil
+}
 
-		switch v := to.(type) {
-		case xml.StartElement:
-			elem = strings.ToLower(v.Name.Local)
-			if ok := validateElements(elem, vld.whiteListElements); !ok {
-				return fmt.Errorf("%w: %s", ErrInvalidElement, v.Name.Local)
-			}
-			var _id, refID string
-			_id, refID, err = validateAttributes(v.Attr, vld.whiteListAttributes, vld.attrValueValidator)
-			if err != nil {
-				return err
+// networkReference records the fetching constructs found in offline mode and
+// reports whether the current token must be dropped
+func (w *walker) networkReference(refs ...string) bool {
+	if len(refs) == 0 {
+		return false
+	}
+	if !w.sanitize() {
+		for _, ref := range refs {
+			w.network = append(w.network, w.elem+` `+ref)
+		}
+	}
+	return true
+}
+
+func (w *walker) sanitize() bool {
+	return w.out != nil
+}
+
+func (w *walker) token(to xml.Token) (err error) {
+	switch v := to.(type) {
+	case xml.StartElement:
+		w.depth++
+		if w.skip > 0 {
+			w.skip++
+			return
+		}
+		if w.inStyle {
+			// would split the stylesheet, and be written into it
+			if w.sanitize() {
+				w.skip = 1
+				return
 			}
-			parent, ok := usec[id]
-			if !ok {
-				parent = root
+			return fmt.Errorf("%w: %s in style", ErrInvalidElement, v.Name.Local)
+		}
+		elem := strings.ToLower(v.Name.Local)
+		if w.depth == 1 && w.vld.rootCheck != RootCheckNone {
+			var drop bool
+			if drop, err = w.checkRoot(v, elem); err != nil {
+				return
 			}
-			if len(_id) > 0 {
-				id = _id
-				id4El = elem
-				usec.New(parent, id)
+			if drop {
+				w.skip = 1
+				return
 			}
-			if elem == `use` {
-				if len(refID) == 0 {
-					parent = nil
-				}
-				if err = usec.Add(parent, refID, 1); err != nil {
-					return err
-				}
-				if parent == nil {
-					*root = *usec[refID]
+		}
+		if w.vld.editorNamespaces {
+			if err = w.editorElement(v.Name); err != nil {
+				if w.sanitize() {
+					w.skip = 1
+					return nil
 				}
-				//fmt.Printf("---------------------------->%+v\n", usec)
+				return
 			}
-		case xml.EndElement:
-			if id4El == elem {
-				id = ``
+		}
+		if _, ok := networkElements[elem]; ok && w.vld.offline {
+			if !w.sanitize() {
+				w.network = append(w.network, `<`+v.Name.Local+`>`)
 			}
-			elem = ``
-			if ok := validateElements(strings.ToLower(v.Name.Local), vld.whiteListElements); !ok {
-				return fmt.Errorf("%w: %s", ErrInvalidElement, v.Name.Local)
+			w.skip = 1
+			return
+		}
+		if ok := validateElements(elem, w.vld.whiteListElements); !ok {
+			if w.sanitize() {
+				w.skip = 1
+				return
+			}
+			return fmt.Errorf("%w: %s", ErrInvalidElement, v.Name.Local)
+		}
+		if w.vld.strictCase {
+			if err = w.checkCase(&v.Name, elem); err != nil {
+				return
+			}
+		}
+		w.elem = elem
+		if elem == `style` {
+			w.inStyle = true
+			w.css = w.css[:0]
+		}
+		var _id, refID string
+		v.Attr, _id, refID, err = w.attributes(v.Attr)
+		if err != nil {
+			return
+		}
+		if w.vld.cssScope != nil && w.sanitize() {
+			w.scope(&v)
+			if w.depth == 1 && len(_id) > 0 {
+				_id = w.scopeID
 			}
-		case xml.CharData: //text
-			if len(elem) > 0 {
-				if fn, ok := vld.innerTextValidator[elem]; ok {
-					if err := fn(v); err != nil {
-						return err
+		}
+		parent, ok := w.usec[w.id]
+		if !ok {
+			parent = w.root
+		}
+		if len(_id) > 0 {
+			w.id = _id
+			w.id4El = elem
+			w.usec.New(parent, w.id)
+		}
+		if elem == `use` {
+			if len(refID) == 0 {
+				parent = nil
+			}
+			if err = w.usec.Add(parent, refID, 1); err != nil {
+				return
+			}
+			if parent == nil {
+				*w.root = *w.usec[refID]
+			}
+			//fmt.Printf("---------------------------->%+v\n", usec)
+		}
+		if w.sanitize() {
+			w.out.StartElement(v)
+		}
+	case xml.EndElement:
+		w.depth--
+		if w.skip > 0 {
+			w.skip--
+			return
+		}
+		if w.inStyle {
+			w.inStyle = false
+			if err = w.stylesheet(); err != nil {
+				return
+			}
+		}
+		if w.id4El == w.elem {
+			w.id = ``
+		}
+		w.elem = ``
+		if ok := validateElements(strings.ToLower(v.Name.Local), w.vld.whiteListElements); !ok {
+			return fmt.Errorf("%w: %s", ErrInvalidElement, v.Name.Local)
+		}
+		if w.sanitize() {
+			w.out.EndElement(v)
+		}
+	case xml.CharData: //text
+		if w.skip > 0 {
+			return
+		}
+		if w.inStyle {
+			w.css = append(w.css, v...)
+			return
+		}
+		if w.depth == 0 && w.vld.rootCheck != RootCheckNone {
+			var drop bool
+			if drop, err = w.checkTopLevelText(v); err != nil || drop {
+				return
+			}
+		}
+		if len(w.elem) > 0 {
+			if fn, ok := w.vld.innerTextValidator[w.elem]; ok {
+				if err = fn(v); err != nil {
+					if w.sanitize() {
+						return nil
 					}
+					return
 				}
 			}
+		}
+		if w.sanitize() {
+			w.out.CharData(v)
+		}
 
-		case xml.Comment: // <!--...-->
+	case xml.Comment: // <!--...-->
 
-		case xml.ProcInst: // <?target inst?>
-			if !strings.EqualFold(v.Target, `xml`) {
-				return fmt.Errorf("%w: %s", ErrInvalidElement, v.Target)
-			}
-
-		case xml.Directive: // <!...> doctype etc
-			d := bytes.TrimSpace(v)
-			length := len(d)
-			if length > 8 && bytes.EqualFold(d[0:7], doctypeBytes) {
-				if entitySystemRegexp.Match(d) {
-					return fmt.Errorf("%w: %s", ErrUnallowedEntityAttribute, d)
-				}
+	case xml.ProcInst: // <?target inst?>
+		if w.inStyle {
+			if w.sanitize() {
+				return
 			}
+			return fmt.Errorf("%w: %s in style", ErrInvalidProcInst, v.Target)
+		}
+		var drop bool
+		if drop, err = w.checkProcInst(&v); err != nil || drop {
+			return
+		}
+		if w.sanitize() {
+			w.out.ProcInst(v)
 		}
 
+	case xml.Directive: // <!...> doctype etc
+		var drop bool
+		if drop, err = w.checkDirective(v); err != nil || drop {
+			return
+		}
+		if w.sanitize() {
+			w.out.Directive(v)
+		}
 	}
-
-	return nil
+	return
 }
 
-// WhitelistElements adds svg elements to the whitelist
+// WhitelistElements adds svg elements to the whitelist, in their canonical
+// spelling. Lowercase names keep the known canonical spelling.
 func (vld *Validator) WhitelistElements(elements ...string) *Validator {
 	for _, elemet := range elements {
-		elemet = strings.ToLower(elemet)
-		vld.whiteListElements[elemet] = struct{}{}
+		addName(vld.whiteListElements, elemet)
 	}
 	return vld
 }
 
-// WhitelistAttributes adds svg attributes to the whitelist
+// WhitelistAttributes adds svg attributes to the whitelist, in their canonical spelling
 func (vld *Validator) WhitelistAttributes(attributes ...string) *Validator {
 	for _, attr := range attributes {
-		attr = strings.ToLower(attr)
-		vld.whiteListAttributes[attr] = struct{}{}
+		addName(vld.whiteListAttributes, attr)
+	}
+	return vld
+}
+
+// WhitelistElementAttributes adds svg attributes to the whitelist of a single
+// element, in their canonical spelling
+func (vld *Validator) WhitelistElementAttributes(element string, attributes ...string) *Validator {
+	element = strings.ToLower(element)
+	allowed, ok := vld.elementAttributes[element]
+	if !ok {
+		allowed = map[string]string{}
+		vld.elementAttributes[element] = allowed
+	}
+	for _, attr := range attributes {
+		addName(allowed, attr)
 	}
 	return vld
 }
@@ -169,85 +399,189 @@ func (vld *Validator) BlacklistElements(elements ...string) *Validator {
 	return vld
 }
 
-// BlacklistAttributes removes svg attributes from the whitelist
+// BlacklistAttributes removes svg attributes from the whitelist, including
+// the whitelists of single elements
 func (vld *Validator) BlacklistAttributes(attributes ...string) *Validator {
 	for _, attr := range attributes {
 		attr = strings.ToLower(attr)
 		delete(vld.whiteListAttributes, attr)
+		for _, allowed := range vld.elementAttributes {
+			delete(allowed, attr)
+		}
+	}
+	return vld
+}
+
+// attributeName returns the canonical spelling of a whitelisted attribute of elem
+func (vld *Validator) attributeName(elem string, key string) (string, bool) {
+	if canonical, ok := vld.whiteListAttributes[key]; ok {
+		return canonical, true
 	}
+	canonical, ok := vld.elementAttributes[elem][key]
+	return canonical, ok
+}
+
+// SetURLPolicy restricts the urls referenced by attributes, nil removes the restriction
+func (vld *Validator) SetURLPolicy(policy *URLPolicy) *Validator {
+	vld.urlPolicy = policy
 	return vld
 }
 
 func (vld *Validator) SetInnerTextValidator(element string, validate func([]byte) error) *Validator {
+	vld.customValidators = true
 	element = strings.ToLower(element)
 	vld.innerTextValidator[element] = validate
 	return vld
 }
 
 func (vld *Validator) SetAttrValueValidator(attribute string, validate func(string) error) *Validator {
+	vld.customValidators = true
 	attribute = strings.ToLower(attribute)
 	vld.attrValueValidator[attribute] = validate
 	return vld
 }
 
 func (vld *Validator) RemoveInnerTextValidator(element string) *Validator {
+	vld.customValidators = true
 	element = strings.ToLower(element)
 	delete(vld.innerTextValidator, element)
 	return vld
 }
 
 func (vld *Validator) RemoveAttrValueValidator(attribute string) *Validator {
+	vld.customValidators = true
 	attribute = strings.ToLower(attribute)
 	delete(vld.attrValueValidator, attribute)
 	return vld
 }
 
-func validateAttributes(attrs []xml.Attr, whiteListAttributes map[string]struct{}, attrValueValidator map[string]func(string) error) (id string, refID string, err error) {
-	var key string
+// attributes validates the attributes of an element. In sanitize mode it
+// returns the attributes that passed validation.
+func (w *walker) attributes(attrs []xml.Attr) (kept []xml.Attr, id string, refID string, err error) {
+	if w.sanitize() {
+		kept = make([]xml.Attr, 0, len(attrs))
+	}
 	for _, attr := range attrs {
-		if len(attr.Name.Space) > 0 {
-			switch attr.Name.Space {
-			case "http://www.w3.org/XML/1998/namespace":
-				attr.Name.Space = "xml"
-			case "http://www.w3.org/1999/xlink":
-				attr.Name.Space = "xlink"
-			}
-			key = strings.ToLower(attr.Name.Local)
-			fn, ok := attrValueValidator[key]
-			if ok {
-				if err = fn(attr.Value); err != nil {
+		if w.vld.editorNamespaces && isEditorAttribute(attr) {
+			continue
+		}
+		var key, value string
+		key, value, err = w.vld.validateAttribute(w.elem, attr, w.sanitize())
+		if err != nil {
+			var rewriteErr *RewriteURLError
+			if w.sanitize() && !errors.As(err, &rewriteErr) {
+				err = nil
+				continue
+			}
+			return
+		}
+		if w.vld.offline && w.networkReference(attrNetworkReferences(key, value)...) {
+			continue
+		}
+		if w.vld.inlineSafe {
+			if value, err = w.inlineSafe(key, value); err != nil {
+				return
+			}
+		}
+		if len(w.vld.idPrefix) > 0 && w.sanitize() {
+			value = w.vld.prefixIDs(key, value)
+		}
+		if len(w.scopeReplaced) > 0 && w.sanitize() {
+			value = w.scopeRefs(key, value)
+		}
+		if w.vld.idCheck != SeverityIgnore {
+			if isIDAttribute(key) {
+				var drop bool
+				if drop, err = w.checkID(value); err != nil {
 					return
 				}
+				if drop {
+					continue
+				}
 			}
-			key = strings.ToLower(attr.Name.Space) + ":" + key
-			if strings.HasSuffix(key, `xlink:href`) && strings.HasPrefix(attr.Value, `#`) {
-				refID = strings.TrimPrefix(attr.Value, `#`)
+			w.addIDRefs(key, value)
+		}
+		switch {
+		case key == `id`:
+			id = value
+			if w.depth == 1 {
+				// as written in the stylesheets, before the rewrites
+				w.rootID = attr.Value
 			}
-		} else {
-			key = strings.ToLower(attr.Name.Local)
-			if key == `id` {
-				id = attr.Value
+		case strings.HasSuffix(key, `xlink:href`) && strings.HasPrefix(value, `#`):
+			refID = strings.TrimPrefix(value, `#`)
+		}
+		if w.vld.strictCase {
+			if err = w.checkAttrCase(&attr, key); err != nil {
+				return
 			}
 		}
-		_, found := whiteListAttributes[key]
-		if !found {
-			err = fmt.Errorf("%w: %s", ErrInvalidAttribute, key)
-			return
+		if w.sanitize() {
+			attr.Value = value
+			kept = append(kept, attr)
+		}
+	}
+	return
+}
+
+// validateAttribute returns the lowercased attribute name used for the
+// whitelist lookup and the attribute value, rewritten by the url policy if
+// rewrite is true
+func (vld *Validator) validateAttribute(elem string, attr xml.Attr, rewrite bool) (key string, value string, err error) {
+	value = attr.Value
+	if len(attr.Name.Space) > 0 {
+		switch attr.Name.Space {
+		case nsXML:
+			attr.Name.Space = "xml"
+		case nsXLink:
+			attr.Name.Space = "xlink"
 		}
-		fn, ok := attrValueValidator[key]
+		key = strings.ToLower(attr.Name.Local)
+		fn, ok := vld.attrValueValidator[key]
 		if ok {
-			err = fn(attr.Value)
-		} else {
-			err = validateAttrValue(attr.Value)
+			if err = fn(value); err != nil {
+				return
+			}
 		}
-		if err != nil {
+		key = strings.ToLower(attr.Name.Space) + ":" + key
+	} else {
+		key = strings.ToLower(attr.Name.Local)
+	}
+	_, found := vld.attributeName(elem, key)
+	if !found {
+		if rewrite {
+			// dropped by the caller, spare formatting the error
+			err = ErrInvalidAttribute
+			return
+		}
+		err = fmt.Errorf("%w: %s", ErrInvalidAttribute, key)
+		return
+	}
+	fn, ok := vld.attrValueValidator[key]
+	if ok {
+		err = fn(value)
+	} else {
+		err = validateAttrValue(value)
+	}
+	if err != nil {
+		return
+	}
+	if vld.fragmentHrefs && isHrefAttribute(key) && !strings.HasPrefix(strings.TrimSpace(value), `#`) {
+		err = fmt.Errorf("%w: %s", ErrUnallowedHrefAttributeValue, value)
+		return
+	}
+	if vld.cssRules != nil && key == `style` {
+		if err = vld.cssRules.check(value, true); err != nil {
 			return
 		}
 	}
+	if vld.urlPolicy != nil {
+		value, err = vld.urlPolicy.apply(key, value, rewrite)
+	}
 	return
 }
 
-func validateElements(elm string, whiteListElements map[string]struct{}) bool {
+func validateElements(elm string, whiteListElements map[string]string) bool {
 	_, found := whiteListElements[elm]
 	return found
 }
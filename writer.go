@@ -0,0 +1,151 @@
+package safesvg
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const (
+	nsXML   = `http://www.w3.org/XML/1998/namespace`
+	nsXLink = `http://www.w3.org/1999/xlink`
+	nsSVG   = `http://www.w3.org/2000/svg`
+)
+
+// xmlWriter serializes the tokens returned by xml.Decoder.Token, turning the
+// namespace URLs it resolves back into the prefixes declared in the document
+type xmlWriter struct {
+	w      *bufio.Writer
+	scopes []map[string]string // namespace url => prefix
+	names  []string            // names of the open elements
+	open   bool                // start tag written without its closing '>'
+	err    error
+}
+
+func newXMLWriter(w io.Writer) *xmlWriter {
+	return &xmlWriter{w: bufio.NewWriter(w)}
+}
+
+func (x *xmlWriter) writeString(s string) {
+	if x.err == nil {
+		_, x.err = x.w.WriteString(s)
+	}
+}
+
+var (
+	textEscaper = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`)
+	attrEscaper = strings.NewReplacer(`&`, `&amp;`, `<`, `&lt;`, `>`, `&gt;`, `"`, `&quot;`,
+		"\t", `&#x9;`, "\n", `&#xA;`, "\r", `&#xD;`)
+)
+
+func (x *xmlWriter) escape(s string, escaper *strings.Replacer) {
+	if x.err == nil {
+		_, x.err = escaper.WriteString(x.w, s)
+	}
+}
+
+func (x *xmlWriter) closeStart() {
+	if x.open {
+		x.writeString(`>`)
+		x.open = false
+	}
+}
+
+func (x *xmlWriter) prefix(space string) (string, bool) {
+	if space == nsXML {
+		return `xml`, true
+	}
+	for i := len(x.scopes) - 1; i >= 0; i-- {
+		if p, ok := x.scopes[i][space]; ok {
+			return p, true
+		}
+	}
+	return ``, false
+}
+
+func (x *xmlWriter) name(n xml.Name, isAttr bool) string {
+	if len(n.Space) == 0 {
+		return n.Local
+	}
+	if isAttr && n.Space == `xmlns` {
+		return `xmlns:` + n.Local
+	}
+	p, ok := x.prefix(n.Space)
+	switch {
+	case ok && len(p) > 0:
+		return p + `:` + n.Local
+	case ok, strings.Contains(n.Space, `:`):
+		// default namespace, or an url nobody declared a prefix for
+		return n.Local
+	}
+	// undeclared prefix left as-is by the decoder
+	return n.Space + `:` + n.Local
+}
+
+// StartElement writes a start tag. The closing '>' is delayed so that an
+// element without content can be written as a self-closing tag.
+func (x *xmlWriter) StartElement(v xml.StartElement) {
+	x.closeStart()
+	scope := map[string]string{}
+	for _, attr := range v.Attr {
+		switch {
+		case attr.Name.Space == `xmlns`:
+			scope[attr.Value] = attr.Name.Local
+		case len(attr.Name.Space) == 0 && attr.Name.Local == `xmlns`:
+			scope[attr.Value] = ``
+		}
+	}
+	x.scopes = append(x.scopes, scope)
+	name := x.name(v.Name, false)
+	x.names = append(x.names, name)
+	x.writeString(`<` + name)
+	for _, attr := range v.Attr {
+		x.writeString(` ` + x.name(attr.Name, true) + `="`)
+		x.escape(attr.Value, attrEscaper)
+		x.writeString(`"`)
+	}
+	x.open = true
+}
+
+// EndElement closes the last started element, whose name may have been
+// corrected since the decoder matched it with v
+func (x *xmlWriter) EndElement(v xml.EndElement) {
+	if len(x.names) == 0 {
+		return
+	}
+	if x.open {
+		x.writeString(`/>`)
+		x.open = false
+	} else {
+		x.writeString(`</` + x.names[len(x.names)-1] + `>`)
+	}
+	x.names = x.names[:len(x.names)-1]
+	x.scopes = x.scopes[:len(x.scopes)-1]
+}
+
+func (x *xmlWriter) CharData(v xml.CharData) {
+	x.closeStart()
+	x.escape(string(v), textEscaper)
+}
+
+func (x *xmlWriter) ProcInst(v xml.ProcInst) {
+	x.closeStart()
+	x.writeString(`<?` + v.Target)
+	if len(v.Inst) > 0 {
+		x.writeString(` ` + string(v.Inst))
+	}
+	x.writeString(`?>`)
+}
+
+func (x *xmlWriter) Directive(v xml.Directive) {
+	x.closeStart()
+	x.writeString(`<!` + string(v) + `>`)
+}
+
+func (x *xmlWriter) Flush() error {
+	if x.err != nil {
+		return x.err
+	}
+	return x.w.Flush()
+}